@@ -0,0 +1,26 @@
+package readycash
+
+import "strings"
+
+var (
+	//AirtimeNetworks maps the networks airtime can be bought on to the
+	//network code the api expects for them
+	AirtimeNetworks = map[string]string{
+		"MTN":     "MTN",
+		"AIRTEL":  "AIRTEL",
+		"GLO":     "GLO",
+		"9MOBILE": "ETISALAT",
+	}
+)
+
+//NetworkCodeFor returns the api code of network, which is matched ignoring
+//case and surrounding whitespace
+func NetworkCodeFor(network string) (string, bool) {
+	code, ok := AirtimeNetworks[strings.ToUpper(strings.TrimSpace(network))]
+	return code, ok
+}
+
+func IsNetworkSupported(network string) bool {
+	_, ok := NetworkCodeFor(network)
+	return ok
+}
@@ -0,0 +1,75 @@
+package readycash
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNetworkSupported(t *testing.T) {
+	assert.True(t, IsNetworkSupported("MTN"))
+	assert.True(t, IsNetworkSupported("airtel"))
+	assert.True(t, IsNetworkSupported(" Glo "))
+	assert.True(t, IsNetworkSupported("9mobile"))
+	assert.False(t, IsNetworkSupported("VODAFONE"))
+	assert.False(t, IsNetworkSupported(""))
+}
+
+func TestNetworkCodeFor(t *testing.T) {
+	code, ok := NetworkCodeFor("9mobile")
+	assert.True(t, ok)
+	assert.Equal(t, "ETISALAT", code)
+
+	code, ok = NetworkCodeFor("mtn")
+	assert.True(t, ok)
+	assert.Equal(t, "MTN", code)
+
+	code, ok = NetworkCodeFor("VODAFONE")
+	assert.False(t, ok)
+	assert.Empty(t, code)
+}
+
+func TestPurchaseAirtimeSendsNetworkCode(t *testing.T) {
+
+	var payload map[string]interface{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseAirtimeUrl {
+			json.NewDecoder(req.Body).Decode(&payload)
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070110", "status": "SUCCESSFUL", "amount": 200}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	if _, err := apiClient.PurchaseAirtime("+234 803 123 4567", 200, "9mobile", "user-defined-ref"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "ETISALAT", payload["network"])
+	assert.Equal(t, "08031234567", payload["phone"])
+
+	payload = nil
+	_, err = apiClient.PurchaseAirtime("0803", 200, "MTN", "user-defined-ref")
+	assert.ErrorIs(t, err, ErrInvalidPhone)
+	assert.Nil(t, payload)
+}
@@ -0,0 +1,22 @@
+package readycash
+
+import "errors"
+
+//Sentinel errors for failures the api reports through ErrorResponse.Code,
+//use errors.Is(err, ErrInsufficientFunds) instead of matching the message
+var (
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrInvalidPin         = errors.New("incorrect pin")
+	ErrDuplicateReference = errors.New("duplicate transaction reference")
+	ErrInvalidAccount     = errors.New("invalid account")
+	ErrLimitExceeded      = errors.New("transaction limit exceeded")
+)
+
+//apiErrorCodes maps the iso 8583 style codes the api returns to sentinels
+var apiErrorCodes = map[int]error{
+	14: ErrInvalidAccount,
+	51: ErrInsufficientFunds,
+	55: ErrInvalidPin,
+	61: ErrLimitExceeded,
+	94: ErrDuplicateReference,
+}
@@ -0,0 +1,46 @@
+package readycash
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorResponseMatchesSentinels(t *testing.T) {
+
+	tests := []struct {
+		code     int
+		sentinel error
+	}{
+		{code: 51, sentinel: ErrInsufficientFunds},
+		{code: 55, sentinel: ErrInvalidPin},
+		{code: 94, sentinel: ErrDuplicateReference},
+		{code: 14, sentinel: ErrInvalidAccount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sentinel.Error(), func(t *testing.T) {
+			err := fmt.Errorf("transfer failed: %w", NewErrorResponse(http.StatusBadRequest, tt.code, "declined", ""))
+			assert.ErrorIs(t, err, tt.sentinel)
+			for _, other := range tests {
+				if other.sentinel != tt.sentinel {
+					assert.NotErrorIs(t, err, other.sentinel)
+				}
+			}
+		})
+	}
+
+	t.Run("unmapped code", func(t *testing.T) {
+		err := NewErrorResponse(http.StatusBadRequest, 999, "something else", "")
+		for _, tt := range tests {
+			assert.False(t, errors.Is(err, tt.sentinel))
+		}
+		var errorResponse *ErrorResponse
+		if assert.ErrorAs(t, err, &errorResponse) {
+			assert.Equal(t, 999, errorResponse.Code)
+		}
+	})
+}
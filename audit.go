@@ -0,0 +1,54 @@
+package readycash
+
+import (
+	"context"
+	"time"
+)
+
+//AuditRecord describes the outcome of one money moving call, it carries no
+//pin, card number or request payload so it is safe to keep in an audit log
+type AuditRecord struct {
+	Operation      string
+	Amount         float64
+	Reference      string
+	TransactionRef string
+	Status         string
+	Err            error
+	Time           time.Time
+}
+
+//AuditSink receives a record for every GenerateUSSD, fund transfer, airtime
+//purchase, bill payment and reversal once its outcome is known, failed ones
+//included. Audit is called on the goroutine making the call so it should
+//not block
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+type auditedKey struct{}
+
+//shouldAudit reports whether the call made with ctx still has to be
+//audited, a call the audit wraps and its retries carry the auditedKey.
+//Every audited call is short circuited in dry run mode, so nothing that
+//reaches the api goes unaudited
+func (r *Client) shouldAudit(ctx context.Context) bool {
+	if r.auditSink == nil || r.dryRun {
+		return false
+	}
+	audited, _ := ctx.Value(auditedKey{}).(bool)
+	return !audited
+}
+
+func withAudited(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditedKey{}, true)
+}
+
+//audit stamps record and hands it to the sink, a failed call without a
+//status of its own is reported as StatusFailed
+func (r *Client) audit(record AuditRecord) {
+	if record.Err != nil && record.Status == "" {
+		record.Status = string(StatusFailed)
+	}
+	record.Time = r.clock.Now()
+	r.auditSink.Audit(record)
+}
@@ -0,0 +1,177 @@
+package readycash
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type capturingSink struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (s *capturingSink) Audit(record AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+}
+
+func TestAuditSinkRecordsTransfers(t *testing.T) {
+
+	testResults := struct {
+		forbidNext bool
+		status     int
+		body       string
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl {
+			if testResults.forbidNext {
+				testResults.forbidNext = false
+				rw.WriteHeader(http.StatusForbidden)
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(testResults.status)
+			rw.Write([]byte(testResults.body))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	sink := &capturingSink{}
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithAuditSink(sink), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	transfer := BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Narration:     "school fees",
+		Reference:     "REF-0001",
+	}
+
+	testResults.forbidNext = true
+	testResults.status = http.StatusOK
+	testResults.body = `{"transactionRef": "RC-0001", "status": "SUCCESSFUL", "amount": 2500, "fee": 25}`
+	if _, err := apiClient.BankFundsTransfer(transfer); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	testResults.status = http.StatusBadRequest
+	testResults.body = `{"Status": 400, "Code": 51, "Message": "insufficient funds"}`
+	transfer.Reference = "REF-0002"
+	_, err = apiClient.BankFundsTransfer(transfer)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	transfer.Amount = 0
+	transfer.Reference = "REF-0003"
+	_, err = apiClient.BankFundsTransfer(transfer)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	if assert.Len(t, sink.records, 3) {
+		assert.Equal(t, AuditRecord{
+			Operation:      "BankFundsTransfer",
+			Amount:         2500,
+			Reference:      "REF-0001",
+			TransactionRef: "RC-0001",
+			Status:         "SUCCESSFUL",
+			Time:           clock.Now(),
+		}, sink.records[0])
+
+		failed := sink.records[1]
+		assert.Equal(t, "REF-0002", failed.Reference)
+		assert.Equal(t, string(StatusFailed), failed.Status)
+		assert.ErrorIs(t, failed.Err, ErrInsufficientFunds)
+		assert.Empty(t, failed.TransactionRef)
+
+		assert.ErrorIs(t, sink.records[2].Err, ErrInvalidAmount)
+	}
+
+	encodedPin := apiClient.session().encodedPin
+	for _, record := range sink.records {
+		assert.NotContains(t, fmt.Sprintf("%+v", record), encodedPin)
+		assert.NotContains(t, fmt.Sprintf("%+v", record), testAccount.Pin)
+	}
+
+	dryRunSink := &capturingSink{}
+	dryRunClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithAuditSink(dryRunSink), WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	transfer.Amount = 100
+	if _, err := dryRunClient.BankFundsTransfer(transfer); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Empty(t, dryRunSink.records)
+}
+
+func TestAuditSinkReversalsUnderDryRun(t *testing.T) {
+
+	var requests []string
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == reverseTransactionUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070109", "amount": 2500, "status": "SUCCESSFUL"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	dryRunSink := &capturingSink{}
+	dryRunClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithAuditSink(dryRunSink), WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := dryRunClient.ReverseTransaction("0000000000001070109", "customer request"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Empty(t, requests, "a dry run reversal should not reach the api")
+	assert.Empty(t, dryRunSink.records)
+
+	sink := &capturingSink{}
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithAuditSink(sink))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := apiClient.ReverseTransaction("0000000000001070109", "customer request"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, []string{baseLoginUrl, reverseTransactionUrl}, requests)
+	if assert.Len(t, sink.records, 1) {
+		assert.Equal(t, "ReverseTransaction", sink.records[0].Operation)
+		assert.Equal(t, "SUCCESSFUL", sink.records[0].Status)
+	}
+}
@@ -0,0 +1,169 @@
+package readycash
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+//ErrBankNotFound is returned by ResolveBankCode when no institution matches the name
+var ErrBankNotFound = errors.New("bank not found")
+
+//defaultBanksCacheTTL is how long ListBanks reuses the institution list
+const defaultBanksCacheTTL = 24 * time.Hour
+
+//maxBankSuggestions is how many close matches are listed when a name does not resolve
+const maxBankSuggestions = 3
+
+//RefreshBanks gets the institution list from the api, skipping the cached
+//copy, and caches the result for later ListBanks calls
+func (r *Client) RefreshBanks() ([]Bank, error) {
+	return r.RefreshBanksContext(context.Background())
+}
+
+//RefreshBanksContext is like RefreshBanks but uses ctx for the request
+func (r *Client) RefreshBanksContext(ctx context.Context) ([]Bank, error) {
+	banks, err := r.fetchBanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(banks) == 0 {
+		return banks, nil
+	}
+
+	data, err := json.Marshal(banks)
+	if err == nil {
+		err = r.storage.SetStringForContext(ctx, r.banksCacheKey(), string(data), r.banksCacheDuration())
+	}
+	if err != nil {
+		r.logger.WithError(err).Warn("could not cache the institution list")
+	}
+	return banks, nil
+}
+
+//cachedBanks returns the institution list held in storage, if any
+func (r *Client) cachedBanks(ctx context.Context) ([]Bank, bool) {
+	data, err := r.storage.GetStringContext(ctx, r.banksCacheKey())
+	if err != nil || data == "" {
+		return nil, false
+	}
+	banks, err := NewBanks([]byte(data))
+	if err != nil || len(banks) == 0 {
+		return nil, false
+	}
+	return banks, true
+}
+
+func (r *Client) banksCacheKey() string {
+	return fmt.Sprintf("%x-banks", md5.Sum([]byte(r.baseURL+listBanks)))
+}
+
+func (r *Client) banksCacheDuration() time.Duration {
+	if r.banksCacheTTL <= 0 {
+		return defaultBanksCacheTTL
+	}
+	return r.banksCacheTTL
+}
+
+//ResolveBankCode returns the institution code of the bank called name,
+//ignoring case and extra whitespace. A name that is part of exactly one
+//bank's name also resolves, otherwise ErrBankNotFound is returned with the
+//closest bank names in its message
+func (r *Client) ResolveBankCode(name string) (string, error) {
+	return r.ResolveBankCodeContext(context.Background(), name)
+}
+
+//ResolveBankCodeContext is like ResolveBankCode but uses ctx for the request
+func (r *Client) ResolveBankCodeContext(ctx context.Context, name string) (string, error) {
+	query := normalizeBankName(name)
+	if query == "" {
+		return "", fmt.Errorf("%w: name is empty", ErrBankNotFound)
+	}
+
+	banks, err := r.ListBanksContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var partial []Bank
+	for _, bank := range banks {
+		bankName := normalizeBankName(bank.Name)
+		if bankName == query {
+			return bank.Code, nil
+		}
+		if strings.Contains(bankName, query) {
+			partial = append(partial, bank)
+		}
+	}
+	if len(partial) == 1 {
+		return partial[0].Code, nil
+	}
+
+	suggestions := closeBankMatches(query, banks, partial)
+	if len(suggestions) == 0 {
+		return "", fmt.Errorf("%w: %q", ErrBankNotFound, name)
+	}
+	return "", fmt.Errorf("%w: %q, did you mean %s", ErrBankNotFound, name, strings.Join(suggestions, ", "))
+}
+
+func normalizeBankName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+//closeBankMatches lists the banks that partially matched the query, or
+//when none did, the banks whose names are the fewest edits away from it
+func closeBankMatches(query string, banks, partial []Bank) []string {
+	candidates := partial
+	if len(candidates) == 0 {
+		for _, bank := range banks {
+			if editDistance(query, normalizeBankName(bank.Name)) <= len(query)/3+1 {
+				candidates = append(candidates, bank)
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return editDistance(query, normalizeBankName(candidates[i].Name)) < editDistance(query, normalizeBankName(candidates[j].Name))
+	})
+
+	var names []string
+	for i := 0; i < len(candidates) && i < maxBankSuggestions; i++ {
+		names = append(names, candidates[i].Name)
+	}
+	return names
+}
+
+//editDistance is the levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
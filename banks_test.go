@@ -0,0 +1,169 @@
+package readycash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBanksServer(counter *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == listBanks {
+			*counter += 1
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`[
+				{"code": "044", "name": "ACCESS BANK PLC"},
+				{"code": "011", "name": "FIRST BANK OF NIGERIA"},
+				{"code": "214", "name": "FIRST CITY MONUMENT BANK"},
+				{"code": "058", "name": "GUARANTY TRUST BANK PLC"},
+				{"code": "057", "name": "ZENITH BANK PLC"}
+			]`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+}
+
+func TestResolveBankCode(t *testing.T) {
+
+	var listBanksCounter int
+	server := newTestBanksServer(&listBanksCounter)
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for name, code := range map[string]string{
+		"ZENITH BANK PLC":             "057",
+		"  guaranty   trust bank plc": "058",
+		"Guaranty Trust":              "058",
+		"access":                      "044",
+		"first bank":                  "011",
+	} {
+		resolved, err := apiClient.ResolveBankCode(name)
+		if err != nil {
+			t.Fatalf("Did not expect %q to fail: %v", name, err)
+		}
+		assert.Equal(t, code, resolved, name)
+	}
+}
+
+func TestResolveBankCodeSuggestsCloseMatches(t *testing.T) {
+
+	var listBanksCounter int
+	server := newTestBanksServer(&listBanksCounter)
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.ResolveBankCode("Zenith Bnak PLC")
+	assert.ErrorIs(t, err, ErrBankNotFound)
+	assert.Contains(t, err.Error(), "ZENITH BANK PLC")
+
+	_, err = apiClient.ResolveBankCode("first")
+	assert.ErrorIs(t, err, ErrBankNotFound)
+	assert.Contains(t, err.Error(), "FIRST BANK OF NIGERIA")
+	assert.Contains(t, err.Error(), "FIRST CITY MONUMENT BANK")
+
+	_, err = apiClient.ResolveBankCode("Moniepoint")
+	assert.ErrorIs(t, err, ErrBankNotFound)
+
+	_, err = apiClient.ResolveBankCode("   ")
+	assert.ErrorIs(t, err, ErrBankNotFound)
+}
+
+func TestEditDistance(t *testing.T) {
+
+	assert.Equal(t, 0, editDistance("zenith", "zenith"))
+	assert.Equal(t, 2, editDistance("bnak", "bank"))
+	assert.Equal(t, 3, editDistance("", "gtb"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
+
+func TestListBanksIsCached(t *testing.T) {
+
+	var listBanksCounter int
+	server := newTestBanksServer(&listBanksCounter)
+	defer server.Close()
+
+	store := NewMockStore()
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	banks, err := apiClient.ListBanks()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Len(t, banks, 5)
+	assert.Equal(t, 1, listBanksCounter)
+
+	cached, err := apiClient.ListBanks()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, banks, cached)
+	assert.Equal(t, 1, listBanksCounter)
+
+	if _, err := apiClient.ResolveBankCode("zenith"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 1, listBanksCounter)
+
+	otherClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := otherClient.ListBanks(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 1, listBanksCounter, "clients sharing a store should share the list")
+
+	if _, err := apiClient.RefreshBanks(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, listBanksCounter)
+}
+
+func TestListBanksCacheExpires(t *testing.T) {
+
+	var listBanksCounter int
+	server := newTestBanksServer(&listBanksCounter)
+	defer server.Close()
+
+	ttl := 20 * time.Millisecond
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithBanksCacheTTL(ttl))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := apiClient.ListBanks(); err != nil {
+			t.Fatalf("Did not expect call to fail: %v", err)
+		}
+	}
+	assert.Equal(t, 1, listBanksCounter)
+
+	time.Sleep(5 * ttl)
+
+	if _, err := apiClient.ListBanks(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, listBanksCounter)
+}
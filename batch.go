@@ -0,0 +1,85 @@
+package readycash
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+const defaultBatchConcurrency = 4
+
+//BatchResult is the outcome of one transfer in a BatchBankTransfer call
+type BatchResult struct {
+	Index          int
+	Request        BankTransferRequest
+	Response       *TransferResponse
+	TransactionRef string
+	Err            error
+}
+
+//Succeeded reports whether the transfer went through
+func (b BatchResult) Succeeded() bool {
+	return b.Err == nil
+}
+
+//BatchBankTransfer makes the transfers in items, at most the number set
+//with WithBatchConcurrency at a time. Each item gets its own result in the
+//order of items and a failed transfer does not stop the others. The error
+//is only set when ctx ended before every transfer was attempted. A key set
+//with WithIdempotencyKey is not shared, each transfer sends the key joined
+//with its Reference, or its index when it has none
+func (r *Client) BatchBankTransfer(ctx context.Context, items []BankTransferRequest) ([]BatchResult, error) {
+	concurrency := r.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	results := make([]BatchResult, len(items))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var skipped error
+
+	for i, item := range items {
+		results[i] = BatchResult{Index: i, Request: item}
+		if err := ctx.Err(); err != nil {
+			results[i].Err, skipped = err, err
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			results[i].Err, skipped = ctx.Err(), ctx.Err()
+			continue
+		case slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(result *BatchResult) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			resp, err := r.BankFundsTransferContext(batchItemContext(ctx, result.Index, result.Request), result.Request)
+			result.Response, result.Err = resp, err
+			if resp != nil {
+				result.TransactionRef = resp.TransactionRef
+			}
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results, skipped
+}
+
+//batchItemContext gives the transfer at index its own idempotency key when
+//ctx carries one, so the api does not take the batch for one transfer
+func batchItemContext(ctx context.Context, index int, item BankTransferRequest) context.Context {
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" {
+		return ctx
+	}
+	suffix := item.Reference
+	if suffix == "" {
+		suffix = strconv.Itoa(index)
+	}
+	return WithIdempotencyKey(ctx, key+"-"+suffix)
+}
@@ -0,0 +1,247 @@
+package readycash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchBankTransfer(t *testing.T) {
+
+	var mu sync.Mutex
+	testResults := struct {
+		inFlight    int
+		maxInFlight int
+		transfers   int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl {
+			var payload map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&payload)
+
+			mu.Lock()
+			testResults.transfers += 1
+			testResults.inFlight += 1
+			if testResults.inFlight > testResults.maxInFlight {
+				testResults.maxInFlight = testResults.inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			testResults.inFlight -= 1
+			mu.Unlock()
+
+			reference, _ := payload["ref"].(string)
+			if strings.HasPrefix(reference, "fail") {
+				rw.Header().Add("content-type", "application/json")
+				rw.WriteHeader(http.StatusBadRequest)
+				rw.Write([]byte(`{"Status": 400, "Code": 14, "Message": "invalid account"}`))
+				return
+			}
+
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(fmt.Sprintf(`{"transactionRef": "tx-%s", "status": "SUCCESSFUL"}`, reference)))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithBatchConcurrency(2))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	references := []string{"ok-1", "fail-2", "ok-3", "ok-4", "fail-5", "ok-6"}
+	items := make([]BankTransferRequest, len(references))
+	for i, reference := range references {
+		items[i] = BankTransferRequest{
+			Amount:        1000,
+			AccountNumber: "0123456789",
+			BankCode:      "058",
+			Reference:     reference,
+		}
+	}
+
+	results, err := apiClient.BatchBankTransfer(context.Background(), items)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Len(t, results, len(items))
+	assert.Equal(t, len(items), testResults.transfers)
+	assert.LessOrEqual(t, testResults.maxInFlight, 2)
+
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, references[i], result.Request.Reference)
+		if strings.HasPrefix(references[i], "fail") {
+			assert.False(t, result.Succeeded())
+			assert.ErrorIs(t, result.Err, ErrInvalidAccount)
+			assert.Empty(t, result.TransactionRef)
+			continue
+		}
+		assert.True(t, result.Succeeded())
+		assert.Equal(t, "tx-"+references[i], result.TransactionRef)
+	}
+}
+
+func TestBatchBankTransferCancelled(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Errorf("Did not expect a request to %s", req.URL.String())
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	items := []BankTransferRequest{{Amount: 1000, Reference: "ref-1"}, {Amount: 1000, Reference: "ref-2"}}
+	results, err := apiClient.BatchBankTransfer(ctx, items)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, results, 2)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
+
+func TestBatchBankTransferIdempotencyKeys(t *testing.T) {
+
+	var mu sync.Mutex
+	keys := map[string]string{}
+
+	ctx, cancel := context.WithCancel(WithIdempotencyKey(context.Background(), "batch-key"))
+	defer cancel()
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl {
+			var payload map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&payload)
+			reference, _ := payload["ref"].(string)
+
+			mu.Lock()
+			keys[reference] = req.Header.Get(IdempotencyKeyHeader)
+			mu.Unlock()
+
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(fmt.Sprintf(`{"transactionRef": "tx-%s", "status": "SUCCESSFUL"}`, reference)))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithBatchConcurrency(3))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	items := []BankTransferRequest{
+		{Amount: 1000, AccountNumber: "0123456789", BankCode: "058", Reference: "ref-1"},
+		{Amount: 1000, AccountNumber: "0123456789", BankCode: "058", Reference: "ref-2"},
+		{Amount: 1000, AccountNumber: "0123456789", BankCode: "058", Reference: "ref-3"},
+	}
+	results, err := apiClient.BatchBankTransfer(ctx, items)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	for _, result := range results {
+		assert.True(t, result.Succeeded())
+	}
+	assert.Equal(t, map[string]string{
+		"ref-1": "batch-key-ref-1",
+		"ref-2": "batch-key-ref-2",
+		"ref-3": "batch-key-ref-3",
+	}, keys)
+
+	cancel()
+	assert.Equal(t, "batch-key-1", idempotencyKeyFromContext(batchItemContext(ctx, 1, BankTransferRequest{})))
+	assert.Empty(t, idempotencyKeyFromContext(batchItemContext(context.Background(), 1, items[0])))
+}
+
+func TestBatchBankTransferCancelledAfterEveryAttempt(t *testing.T) {
+
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl {
+			arrived <- struct{}{}
+			<-release
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "tx-1", "status": "SUCCESSFUL"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithBatchConcurrency(2))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-arrived
+		<-arrived
+		cancel()
+		close(release)
+	}()
+
+	items := []BankTransferRequest{
+		{Amount: 1000, AccountNumber: "0123456789", BankCode: "058", Reference: "ref-1"},
+		{Amount: 1000, AccountNumber: "0123456789", BankCode: "058", Reference: "ref-2"},
+	}
+	results, err := apiClient.BatchBankTransfer(ctx, items)
+	assert.NoError(t, err, "every transfer was attempted before ctx ended")
+	assert.Len(t, results, 2)
+}
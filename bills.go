@@ -0,0 +1,265 @@
+package readycash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//BillPaymentRequest holds the details of a utility bill payment, like
+//electricity or cable tv. CustomerReference is what the biller knows the
+//customer by, a meter or smartcard number
+type BillPaymentRequest struct {
+	BillerCode        string
+	CustomerReference string
+	Amount            float64
+	Reference         string
+}
+
+func (b BillPaymentRequest) validate() error {
+	requiredFields := []struct {
+		name  string
+		value string
+	}{
+		{"billerCode", b.BillerCode},
+		{"customerReference", b.CustomerReference},
+		{"ref", b.Reference},
+	}
+
+	for _, field := range requiredFields {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("%w: %s", ErrMissingRequiredField, field.name)
+		}
+	}
+	return nil
+}
+
+func (b BillPaymentRequest) toPayload(encodedPin string) map[string]interface{} {
+	return map[string]interface{}{
+		"billerCode":        b.BillerCode,
+		"customerReference": b.CustomerReference,
+		"amount":            b.Amount,
+		"ref":               b.Reference,
+		"pin":               encodedPin,
+	}
+}
+
+//BillCustomerResponse is the customer a biller holds under a customer reference
+type BillCustomerResponse struct {
+	BillerCode        string `json:"billerCode"`
+	CustomerReference string `json:"customerReference"`
+	CustomerName      string `json:"customerName"`
+}
+
+func NewBillCustomerResponse(data []byte) (*BillCustomerResponse, error) {
+	var r BillCustomerResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+//BillPaymentResponse returned from the bill payment operation, Token is the
+//prepaid electricity token when the biller issues one
+type BillPaymentResponse struct {
+	TransactionRef string  `json:"transactionRef"`
+	Status         string  `json:"status"`
+	Amount         float64 `json:"amount"`
+	Fee            float64 `json:"fee"`
+	Token          string  `json:"token"`
+}
+
+//NetAmount returns the Amount less the Fee charged on the payment
+func (r *BillPaymentResponse) NetAmount() float64 {
+	return r.Amount - r.Fee
+}
+
+func NewBillPaymentResponse(data []byte) (*BillPaymentResponse, error) {
+	var r BillPaymentResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+//ValidateBillCustomer resolves the customer a biller holds under
+//customerReference, use it to confirm the name before PayBill
+func (r *Client) ValidateBillCustomer(billerCode, customerReference string) (*BillCustomerResponse, error) {
+	return r.ValidateBillCustomerContext(context.Background(), billerCode, customerReference)
+}
+
+//ValidateBillCustomerContext is like ValidateBillCustomer but uses ctx for the request
+func (r *Client) ValidateBillCustomerContext(ctx context.Context, billerCode, customerReference string) (*BillCustomerResponse, error) {
+	ctx = withOperation(ctx, "ValidateBillCustomer")
+	payload := map[string]interface{}{
+		"billerCode":        billerCode,
+		"customerReference": customerReference,
+	}
+
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "ValidateBillCustomer",
+	}, payload)
+
+	if strings.TrimSpace(billerCode) == "" || strings.TrimSpace(customerReference) == "" {
+		reqLogger.Error("biller code and customer reference are required")
+		return nil, fmt.Errorf("%w: billerCode, customerReference", ErrMissingRequiredField)
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(payload)
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	validateUrl := r.generateUrl(validateBillCustomerUrl)
+	request, err := r.newPostRequest(withRetryMode(ctx, retrySafe), validateUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request to validate bill customer")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request to validate bill customer")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.ValidateBillCustomerContext(retryCtx, billerCode, customerReference)
+	}
+
+	if len(data) == 0 {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("validate bill customer response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewBillCustomerResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating bill customer model from response")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+//PayBill pays a utility bill from the wallet
+func (r *Client) PayBill(req BillPaymentRequest) (*BillPaymentResponse, error) {
+	return r.PayBillContext(context.Background(), req)
+}
+
+//PayBillContext is like PayBill but uses ctx for the request
+func (r *Client) PayBillContext(ctx context.Context, req BillPaymentRequest) (*BillPaymentResponse, error) {
+	if r.shouldAudit(ctx) {
+		res, err := r.PayBillContext(withAudited(ctx), req)
+		record := AuditRecord{Operation: "PayBill", Amount: req.Amount, Reference: req.Reference, Err: err}
+		if res != nil {
+			record.TransactionRef, record.Status = res.TransactionRef, res.Status
+		}
+		r.audit(record)
+		return res, err
+	}
+
+	ctx = withOperation(ctx, "PayBill")
+	ctx = ensureIdempotencyKey(ctx)
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method":            "PayBill",
+		"amount":            req.Amount,
+		"billerCode":        req.BillerCode,
+		"customerReference": req.CustomerReference,
+		"ref":               req.Reference,
+	})
+
+	if err := req.validate(); err != nil {
+		reqLogger.WithError(err).Error("bill payment request is not valid")
+		return nil, err
+	}
+
+	if err := r.checkAmount(req.Amount); err != nil {
+		reqLogger.WithError(err).Error("amount is not valid")
+		return nil, err
+	}
+
+	if r.dryRun {
+		r.logDryRun(reqLogger, req.toPayload(""))
+		return dryRunBillPaymentResponse(req.Reference, req.Amount), nil
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(req.toPayload(r.session().encodedPin))
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	billUrl := r.generateUrl(payBillUrl)
+	request, err := r.newPostRequest(withRetryMode(ctx, retryIdempotent), billUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request for bill payment")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request for bill payment")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.PayBillContext(retryCtx, req)
+	}
+
+	if len(data) == 0 {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("bill payment response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewBillPaymentResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating bill payment model from response")
+		return nil, err
+	}
+
+	if res.Amount == 0 {
+		res.Amount = req.Amount
+	}
+	return res, nil
+}
@@ -0,0 +1,139 @@
+package readycash
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBillsServer(payloads map[string]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == validateBillCustomerUrl && req.Method == http.MethodPost {
+			var payload map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&payload)
+			payloads[req.URL.Path] = payload
+
+			rw.Header().Add("content-type", "application/json")
+			if payload["customerReference"] != "45700000001" {
+				rw.WriteHeader(http.StatusBadRequest)
+				rw.Write([]byte(`{"Status": 400, "Code": 14, "Message": "invalid meter number"}`))
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"billerCode": "IKEDC", "customerReference": "45700000001", "customerName": "ADA OBI"}`))
+			return
+		}
+
+		if req.URL.String() == payBillUrl && req.Method == http.MethodPost {
+			var payload map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&payload)
+			payloads[req.URL.Path] = payload
+
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070111", "status": "SUCCESSFUL", "fee": 100, "token": "1234-5678-9012-3456"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+}
+
+func TestValidateBillCustomer(t *testing.T) {
+
+	payloads := map[string]map[string]interface{}{}
+	server := newTestBillsServer(payloads)
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.ValidateBillCustomer("IKEDC", " ")
+	assert.ErrorIs(t, err, ErrMissingRequiredField)
+	assert.Empty(t, payloads)
+
+	resp, err := apiClient.ValidateBillCustomer("IKEDC", "45700000001")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, map[string]interface{}{"billerCode": "IKEDC", "customerReference": "45700000001"}, payloads[validateBillCustomerUrl])
+	assert.Equal(t, "ADA OBI", resp.CustomerName)
+	assert.Equal(t, "45700000001", resp.CustomerReference)
+
+	_, err = apiClient.ValidateBillCustomer("IKEDC", "45700000002")
+	assert.ErrorIs(t, err, ErrInvalidAccount)
+}
+
+func TestPayBill(t *testing.T) {
+
+	payloads := map[string]map[string]interface{}{}
+	server := newTestBillsServer(payloads)
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	req := BillPaymentRequest{
+		BillerCode:        "IKEDC",
+		CustomerReference: "45700000001",
+		Amount:            5000,
+		Reference:         "bill-ref-1",
+	}
+
+	invalid := req
+	invalid.BillerCode = ""
+	_, err = apiClient.PayBill(invalid)
+	assert.ErrorIs(t, err, ErrMissingRequiredField)
+
+	invalid = req
+	invalid.Amount = 0
+	_, err = apiClient.PayBill(invalid)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	assert.Empty(t, payloads)
+
+	resp, err := apiClient.PayBill(req)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	expectedPin, _ := DesEncrypt([]byte("1234"), []byte("1234"))
+	assert.Equal(t, map[string]interface{}{
+		"billerCode":        "IKEDC",
+		"customerReference": "45700000001",
+		"amount":            float64(5000),
+		"ref":               "bill-ref-1",
+		"pin":               expectedPin,
+	}, payloads[payBillUrl])
+	assert.Equal(t, "0000000000001070111", resp.TransactionRef)
+	assert.Equal(t, "SUCCESSFUL", resp.Status)
+	assert.Equal(t, "1234-5678-9012-3456", resp.Token)
+	assert.Equal(t, float64(5000), resp.Amount)
+	assert.Equal(t, float64(4900), resp.NetAmount())
+
+	dryRunClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	delete(payloads, payBillUrl)
+	dryRunResp, err := dryRunClient.PayBill(req)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, DryRunRefPrefix+"bill-ref-1", dryRunResp.TransactionRef)
+	assert.NotContains(t, payloads, payBillUrl)
+}
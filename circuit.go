@@ -0,0 +1,93 @@
+package readycash
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//ErrCircuitOpen is returned without contacting the api while the circuit
+//breaker is open after too many consecutive failures
+var ErrCircuitOpen = errors.New("circuit breaker is open, readycash api is failing")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+//circuitBreaker opens after threshold consecutive failures and rejects
+//calls until cooldown has passed, then lets a single trial call through.
+//A successful trial closes it again and a failed one reopens it
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+	trialSent bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+//allow reports ErrCircuitOpen when the call should not be attempted
+func (b *circuitBreaker) allow(now time.Time) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		b.trialSent = true
+		return nil
+	case circuitHalfOpen:
+		if b.trialSent {
+			return ErrCircuitOpen
+		}
+		b.trialSent = true
+	}
+	return nil
+}
+
+//record updates the breaker with the outcome of an allowed call. Calls
+//that ended because the caller's ctx was done say nothing about the api
+//and only free up the half open trial
+func (b *circuitBreaker) record(ctx context.Context, now time.Time, statusCode int, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil && ctx.Err() != nil {
+		b.trialSent = false
+		return
+	}
+
+	if err == nil && statusCode < http.StatusInternalServerError {
+		b.state = circuitClosed
+		b.failures = 0
+		b.trialSent = false
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = now
+		b.trialSent = false
+	}
+}
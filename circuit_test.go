@@ -0,0 +1,159 @@
+package readycash
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+
+	var healthy int32
+	var balanceCalls int32
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			atomic.AddInt32(&balanceCalls, 1)
+			if atomic.LoadInt32(&healthy) == 0 {
+				rw.WriteHeader(http.StatusBadGateway)
+				rw.Write([]byte(`bad gateway`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	cooldown := 50 * time.Millisecond
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithCircuitBreaker(2, cooldown))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := apiClient.BalanceEnquiry()
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&balanceCalls))
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&balanceCalls))
+
+	time.Sleep(cooldown)
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&balanceCalls))
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrCircuitOpen, "a failed trial should open the circuit again")
+
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(cooldown)
+
+	for i := 0; i < 2; i++ {
+		if _, err := apiClient.BalanceEnquiry(); err != nil {
+			t.Fatalf("Did not expect call to fail: %v", err)
+		}
+	}
+	assert.Equal(t, int32(5), atomic.LoadInt32(&balanceCalls))
+}
+
+//failingLimiter fails the next failures waits and lets the rest through
+type failingLimiter struct {
+	failures int32
+}
+
+func (l *failingLimiter) Wait(ctx context.Context) error {
+	if atomic.AddInt32(&l.failures, -1) >= 0 {
+		return errors.New("rate limiter failed")
+	}
+	return nil
+}
+
+func TestCircuitBreakerLoginTrialNotLostBeforeSending(t *testing.T) {
+
+	var healthy int32
+	var loginCalls int32
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			atomic.AddInt32(&loginCalls, 1)
+			if atomic.LoadInt32(&healthy) == 0 {
+				rw.WriteHeader(http.StatusBadGateway)
+				rw.Write([]byte(`bad gateway`))
+				return
+			}
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	limiter := &failingLimiter{}
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(),
+		WithCircuitBreaker(1, time.Minute), WithRateLimiter(limiter), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrLoginFailed)
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loginCalls))
+
+	atomic.StoreInt32(&healthy, 1)
+	clock.Advance(2 * time.Minute)
+	atomic.StoreInt32(&limiter.failures, 1)
+	_, err = apiClient.BalanceEnquiry()
+	assert.EqualError(t, err, "rate limiter failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = apiClient.BalanceEnquiryContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	for _, wait := range []time.Duration{0, 10 * time.Minute} {
+		clock.Advance(wait)
+		if _, err := apiClient.BalanceEnquiry(); err != nil {
+			t.Fatalf("Did not expect call to fail: %v", err)
+		}
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loginCalls))
+}
@@ -2,7 +2,9 @@ package readycash
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +12,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,8 +26,29 @@ var (
 	ErrLoginFailed = errors.New("could not login to account")
 	ErrBankNotSupportedOnUSSD = errors.New("bank not supported on ussd")
 	ErrEmptyResponse = NewServerErrorResponse("Invalid Response Body")
+	ErrInvalidAmount = errors.New("amount must be greater than zero")
+	ErrInvalidPhone = errors.New("phone is not a valid nigerian phone number")
+	ErrInvalidRecipientPhone = ErrInvalidPhone
+	ErrNetworkNotSupported = errors.New("network not supported for airtime")
+	ErrMissingRequiredField = errors.New("required field is missing")
+	ErrForbiddenAfterRetry = errors.New("request was forbidden even after logging in again")
+	ErrAmountOutOfRange = errors.New("amount is outside the configured limits")
+	ErrNameMismatch = errors.New("account name does not match the expected name")
+	ErrInvalidBankCode = errors.New("bank code is not valid")
+	ErrResponseTooLarge = errors.New("response body is larger than the configured limit")
+	ErrLoginMissingCredentials = errors.New("login succeeded but did not return the session headers")
+	ErrSessionExpired = errors.New("session has expired and auto login is disabled")
 )
 
+//Version is the library version sent in the default User-Agent
+const Version = "0.1.0"
+
+const defaultUserAgent = "readycash-go/" + Version
+
+//sessionLengthHeader is the login response header the api may report the
+//granted session length in, in seconds
+const sessionLengthHeader = "X-Session-Length"
+
 type LogLevel int
 
 const (
@@ -35,7 +61,10 @@ const (
 const (
 	providerSchoolable                = "SCHOOLABLE"
 	reversedTransactionType           = "420.00.010.0000"
-	thirtyDays                        = 30 * 24 * 60
+	thirtyDays                        = 30 * 24 * time.Hour
+	maxTransactionPages               = 1000
+	defaultSessionLength              = 30 * time.Minute
+	defaultMaxResponseSize            = 4 << 20
 )
 
 const (
@@ -57,13 +86,41 @@ const (
 	resolvePendingTransaction         = "/rc/rest/agent/transact/pending/resolve"
 	listPendingTransactions           = "/rc/rest/agent/transact/pending/list"
 	listBanks                         = "/rc/rest/common/institutions"
+	changePinUrl                      = "/rc/rest/agent/changepin"
+	reverseTransactionUrl             = "/rc/rest/agent/transact/reverse"
+	validateBillCustomerUrl           = "/rc/rest/agent/transact/bill/validate"
+	payBillUrl                        = "/rc/rest/agent/transact/bill/pay"
 )
 
+//authCacheKey names the storage key the session of an account is kept
+//under, all of authParams is stored as one json value so it is written and
+//read back as a whole
 type authCacheKey struct {
-	authorizationKey string
-	sessionIDKey string
-	expirationKey string
-	encodedPinKey string
+	sessionKey string
+}
+
+//managedHeaders are set by the client and cannot be replaced with WithDefaultHeaders
+var managedHeaders = map[string]struct{}{
+	"Authorization":                        {},
+	http.CanonicalHeaderKey("X-SessionID"): {},
+	"Content-Type":                         {},
+	"User-Agent":                           {},
+	IdempotencyKeyHeader:                   {},
+}
+
+type forbiddenRetryKey struct{}
+
+type operationKey struct{}
+
+//withOperation names the client method a request is made for, so requests
+//can be logged against the operation rather than just the url
+func withOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+func operationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	return operation
 }
 
 type authParams struct {
@@ -73,7 +130,7 @@ type authParams struct {
 	encodedPin string
 }
 
-func (p *authParams) hasExpired() bool {
+func (p authParams) hasExpired(now time.Time) bool {
 	if p.expiration.IsZero() {
 		return true
 	}
@@ -82,11 +139,45 @@ func (p *authParams) hasExpired() bool {
 		return true
 	}
 
-	return time.Now().After(p.expiration)
+	return now.After(p.expiration)
+}
+
+//storedSession is the json form authParams is kept in storage as
+type storedSession struct {
+	Authorization string `json:"authorization"`
+	SessionID     string `json:"sessionId"`
+	EncodedPin    string `json:"encodedPin"`
+	Expiration    int64  `json:"expiration"`
+}
+
+func (p authParams) marshal() (string, error) {
+	data, err := json.Marshal(storedSession{
+		Authorization: p.authorization,
+		SessionID:     p.sessionID,
+		EncodedPin:    p.encodedPin,
+		Expiration:    p.expiration.Unix(),
+	})
+	return string(data), err
+}
+
+func unmarshalAuthParams(data string) (authParams, error) {
+	var stored storedSession
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return authParams{}, err
+	}
+	p := authParams{
+		authorization: stored.Authorization,
+		sessionID:     stored.SessionID,
+		encodedPin:    stored.EncodedPin,
+	}
+	if stored.Expiration > 0 {
+		p.expiration = time.Unix(stored.Expiration, 0)
+	}
+	return p, nil
 }
 
-func (p *authParams) setPin(pin string, key string) error {
-	hexStr, err := DesEncrypt([]byte(pin), []byte(key))
+func (p *authParams) setPin(encryptor PinEncryptor, pin string, key string) error {
+	hexStr, err := encryptor.Encrypt([]byte(pin), []byte(key))
 	p.encodedPin = hexStr
 	return err
 }
@@ -103,6 +194,34 @@ type FetchTransactionOption struct {
 	After *int64 `json:"after"`
 	StartDate *int64 `json:"start_date"`
 	EndDate *int64 `json:"end_date"`
+	//Debit keeps only debits when true and only credits when false. It is
+	//sent to the api and also applied to the transactions returned, in case
+	//the api ignores it
+	Debit *bool `json:"debit"`
+}
+
+//withDefaultWindow limits the options to the thirty days up to now when
+//neither StartDate nor EndDate is set, so the api is never asked for the
+//whole history
+func (o FetchTransactionOption) withDefaultWindow(now time.Time) FetchTransactionOption {
+	if o.StartDate != nil || o.EndDate != nil {
+		return o
+	}
+	o.SetStartTime(now.Add(-thirtyDays))
+	o.SetEndTime(now)
+	return o
+}
+
+//SetStartTime sets StartDate to t in epoch milliseconds
+func (o *FetchTransactionOption) SetStartTime(t time.Time) {
+	startDate := timeToMillis(t)
+	o.StartDate = &startDate
+}
+
+//SetEndTime sets EndDate to t in epoch milliseconds
+func (o *FetchTransactionOption) SetEndTime(t time.Time) {
+	endDate := timeToMillis(t)
+	o.EndDate = &endDate
 }
 
 func (o FetchTransactionOption) ToMap() map[string]string {
@@ -124,16 +243,138 @@ func (o FetchTransactionOption) ToMap() map[string]string {
 		result["end_date"] = fmt.Sprintf("%d", *o.EndDate)
 	}
 
+	if o.Debit != nil {
+		result["debit"] = fmt.Sprintf("%t", *o.Debit)
+	}
+
 	return result
 }
 
+//filterDebit keeps the transactions whose Debit matches debit, all of them
+//when debit is nil
+func filterDebit(transactions []WalletTransaction, debit *bool) []WalletTransaction {
+	if debit == nil {
+		return transactions
+	}
+	filtered := make([]WalletTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.Debit == *debit {
+			filtered = append(filtered, transaction)
+		}
+	}
+	return filtered
+}
+
+//BankTransferRequest holds the details of a transfer to an external bank account
+type BankTransferRequest struct {
+	Amount        float64
+	AccountNumber string
+	BankCode      string
+	Narration     string
+	Reference     string
+}
+
+func (t BankTransferRequest) toPayload(encodedPin string) map[string]interface{} {
+	return map[string]interface{}{
+		"amount":        t.Amount,
+		"accountNumber": t.AccountNumber,
+		"bankCode":      t.BankCode,
+		"narration":     t.Narration,
+		"ref":           t.Reference,
+		"pin":           encodedPin,
+	}
+}
+
+//VirtualAccountRequest holds the details used to generate a virtual bank account
+type VirtualAccountRequest struct {
+	CustomerName string
+	BVN          string
+	Reference    string
+	Expiry       *time.Time
+}
+
+func (v VirtualAccountRequest) toPayload() map[string]interface{} {
+	payload := map[string]interface{}{
+		"name": v.CustomerName,
+		"bvn":  v.BVN,
+		"ref":  v.Reference,
+	}
+
+	if v.Expiry != nil {
+		payload["expiry"] = v.Expiry.Unix() * 1000
+	}
+
+	return payload
+}
+
+//CreateAgentRequest holds the details of the agent to onboard
+type CreateAgentRequest struct {
+	Name    string
+	Phone   string
+	Email   string
+	Address string
+}
+
+func (a CreateAgentRequest) validate() error {
+	requiredFields := []struct {
+		name  string
+		value string
+	}{
+		{"name", a.Name},
+		{"phone", a.Phone},
+		{"email", a.Email},
+		{"address", a.Address},
+	}
+
+	for _, field := range requiredFields {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("%w: %s", ErrMissingRequiredField, field.name)
+		}
+	}
+	return nil
+}
+
+func (a CreateAgentRequest) toPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"name":    a.Name,
+		"phone":   a.Phone,
+		"email":   a.Email,
+		"address": a.Address,
+	}
+}
+
+//RegisterUserRequest holds the details of the end user to register
+type RegisterUserRequest struct {
+	Phone     string
+	FirstName string
+	LastName  string
+	Email     string
+}
+
+func (u RegisterUserRequest) toPayload() map[string]interface{} {
+	payload := map[string]interface{}{
+		"phone":     u.Phone,
+		"firstName": u.FirstName,
+		"lastName":  u.LastName,
+	}
+
+	if u.Email != "" {
+		payload["email"] = u.Email
+	}
+
+	return payload
+}
+
+//Storage caches the login session so it can be reused across client
+//instances. Stores written against the older interface without Delete can
+//be wrapped with NewStorageAdapter
 type Storage interface {
-	SetStringFor(key, val string, exp time.Duration) error
-	SetIntFor(key string, val int64, exp time.Duration) error
-	GetString(key string) (string, error)
-	GetInt(key string) (int64, error)
+	BasicStorage
+	Delete(key string) error
 }
 
+//Account holds the credentials the client logs in with. SessionLength is
+//how long a login stays valid, it defaults to 30 minutes when not positive
 type Account struct {
 	UserName string
 	Password string
@@ -142,12 +383,46 @@ type Account struct {
 }
 
 type Client struct {
-	account        *Account
-	baseURL        string
-	httpClient     *http.Client
-	storage        Storage
-	access         authParams
-	logger         *logrus.Logger
+	account          *Account
+	baseURL          string
+	httpClient       *http.Client
+	storage          ContextStorage
+	accessMu         sync.RWMutex
+	access           authParams
+	pin              string
+	lastLogin        *LoginResult
+	failedLogin      error
+	failedLoginAt    time.Time
+	logger           Logger
+	retryPolicy      RetryPolicy
+	timeout          time.Duration
+	amountLimits     AmountLimits
+	userAgent        string
+	defaultHeaders   map[string]string
+	transport        http.RoundTripper
+	proxyURL         *url.URL
+	tlsConfig        *tls.Config
+	ownsTransport    bool
+	maxIdleConns     int
+	maxConnsPerHost  int
+	idleConnTimeout  time.Duration
+	pinEncryptor     PinEncryptor
+	location         *time.Location
+	batchConcurrency int
+	rateLimiter      RateLimiter
+	breaker          *circuitBreaker
+	dump             *trafficDump
+	onLogin          func(LoginResult)
+	dryRun           bool
+	banksCacheTTL    time.Duration
+	clock            Clock
+	auditSink        AuditSink
+	loginBackoff     time.Duration
+	disableAutoLogin bool
+	maxResponseSize  int64
+	observer         Observer
+	tracer           Tracer
+	logins           flightGroup
 }
 
 func NewClient(
@@ -155,12 +430,14 @@ func NewClient(
 	baseUrl string,
 	storage Storage,
 	httpClient *http.Client,
+	opts ...Option,
 ) (*Client, error) {
 
 	if account == nil ||  account.Pin == "" || account.UserName == "" || account.Password == "" {
 		return nil, ErrAccountCredentialsRequired
 	}
 
+	ownsTransport := httpClient == nil
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
@@ -168,37 +445,55 @@ func NewClient(
 	loggerInstance := logrus.New()
 	loggerInstance.Level = logrus.ErrorLevel
 
-	return &Client{
-		storage: storage,
+	client := &Client{
+		storage: NewContextStorageAdapter(storage),
 		account:    account,
-		baseURL:    baseUrl,
+		pin: account.Pin,
+		baseURL:    normalizeBaseURL(baseUrl),
 		httpClient: httpClient,
-		logger: loggerInstance,
-	}, nil
+		logger: NewLogrusLogger(loggerInstance),
+		observer: noopObserver{},
+		tracer: noopTracer{},
+		userAgent: defaultUserAgent,
+		pinEncryptor: DESPinEncryptor{},
+		location: time.UTC,
+		clock: realClock{},
+		ownsTransport: ownsTransport,
+		maxIdleConns: defaultMaxIdleConns,
+		maxConnsPerHost: defaultMaxConnsPerHost,
+		idleConnTimeout: defaultIdleConnTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.configureTransport()
+
+	return client, nil
 }
 
-//SetLogLevel changes the logging level for client
+//SetLogLevel changes the logging level for client, it has no effect when
+//the configured Logger does not implement LevelSetter
 func (r *Client) SetLogLevel(l LogLevel) {
-	switch l {
-	case Debug:
-		r.logger.Level = logrus.DebugLevel
-	case Info:
-		r.logger.Level = logrus.InfoLevel
-	case Warn:
-		r.logger.Level = logrus.WarnLevel
-	case Error:
-		r.logger.Level = logrus.ErrorLevel
+	if setter, ok := r.logger.(LevelSetter); ok {
+		setter.SetLevel(l)
 	}
 }
 
 //BalanceEnquiry returns the account balance of the current user
 func (r *Client) BalanceEnquiry() (*BalanceEnquiryResponse, error) {
-	if err := r.ensureUserIsAuthenticated(); err != nil {
+	return r.BalanceEnquiryContext(context.Background())
+}
+
+//BalanceEnquiryContext is like BalanceEnquiry but uses ctx for the request
+func (r *Client) BalanceEnquiryContext(ctx context.Context) (*BalanceEnquiryResponse, error) {
+	ctx = withOperation(ctx, "BalanceEnquiry")
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
 		return nil, err
 	}
 
 	balanceURL :=  r.generateUrl(baseBalanceUrl)
-	request, err := r.newGetRequest( balanceURL, nil)
+	request, err := r.newGetRequest(ctx, balanceURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -209,12 +504,15 @@ func (r *Client) BalanceEnquiry() (*BalanceEnquiryResponse, error) {
 	}
 
 	if statusCode == http.StatusForbidden {
-		r.access.reset()
-		return r.BalanceEnquiry()
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.BalanceEnquiryContext(retryCtx)
 	}
 
 	if !r.successCode(statusCode) {
-		return nil, r.toErrorResponse(data)
+		return nil, r.toErrorResponse(statusCode, data)
 	}
 
 	return NewBalanceResponse(data)
@@ -226,6 +524,32 @@ func (r *Client) GenerateUSSD(
 	amount float64,
 	bankCode string,
 ) (*UssdTransactionResponse, error) {
+	return r.GenerateUSSDContext(context.Background(), reference, amount, bankCode)
+}
+
+//GenerateUSSDContext is like GenerateUSSD but uses ctx for the request
+func (r *Client) GenerateUSSDContext(
+	ctx context.Context,
+	reference string,
+	amount float64,
+	bankCode string,
+) (*UssdTransactionResponse, error) {
+	if r.shouldAudit(ctx) {
+		res, err := r.GenerateUSSDContext(withAudited(ctx), reference, amount, bankCode)
+		record := AuditRecord{Operation: "GenerateUSSD", Amount: amount, Reference: reference, Err: err}
+		if res != nil {
+			record.TransactionRef, record.Status = res.TransactionRef, res.Status
+		}
+		r.audit(record)
+		return res, err
+	}
+
+	ctx = withOperation(ctx, "GenerateUSSD")
+	ctx = ensureIdempotencyKey(ctx)
+	normalizedCode, codeErr := NormalizeBankCode(bankCode)
+	if codeErr == nil {
+		bankCode = normalizedCode
+	}
 	payload := map[string]interface{}{
 		"amount":   amount,
 		"bankCode": bankCode,
@@ -237,12 +561,27 @@ func (r *Client) GenerateUSSD(
 	},payload)
 
 
+	if codeErr != nil {
+		reqLogger.WithError(codeErr).Error("bank code is not valid")
+		return nil, codeErr
+	}
+
 	if !IsBankSupportedOnUSSD(bankCode) {
 		reqLogger.Error("bank code not supported")
 		return nil, ErrBankNotSupportedOnUSSD
 	}
 
-	if err := r.ensureUserIsAuthenticated(); err != nil {
+	if err := r.checkAmount(amount); err != nil {
+		reqLogger.WithError(err).Error("amount is not valid")
+		return nil, err
+	}
+
+	if r.dryRun {
+		r.logDryRun(reqLogger, payload)
+		return dryRunUssdResponse(reference, amount, r.clock, r.location), nil
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
 		return nil, err
 	}
 
@@ -256,7 +595,7 @@ func (r *Client) GenerateUSSD(
 	ussdGenerationUrl := r.generateUrl(baseUssdTransaction)
 	reqLogger.WithField("url", ussdGenerationUrl).Debug("ussd request url")
 
-	request, err := r.newPostRequest(ussdGenerationUrl, payloadReader)
+	request, err := r.newPostRequest(withRetryMode(ctx, retryIdempotent), ussdGenerationUrl, payloadReader)
 	if err != nil {
 		reqLogger.WithError(err).Error("encountered error creating post request to generate ussd")
 		return nil, err
@@ -268,6 +607,15 @@ func (r *Client) GenerateUSSD(
 		return nil, err
 	}
 
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.GenerateUSSDContext(retryCtx, reference, amount, bankCode)
+	}
+
 	if data == nil {
 		reqLogger.Error("did not return any response content")
 		return nil, ErrEmptyResponse
@@ -279,7 +627,7 @@ func (r *Client) GenerateUSSD(
 		reqLogger.WithField("status_code",statusCode).
 			WithField("data",string(data)).
 			Error("status code received is not success")
-		return nil, r.toErrorResponse(data)
+		return nil, r.toErrorResponse(statusCode, data)
 	}
 
 	res, err := NewUssdTransactionResponse(data)
@@ -292,6 +640,8 @@ func (r *Client) GenerateUSSD(
 
 	if res != nil {
 		res.UserDefinedReference = reference
+		res.clock = r.clock
+		res.location = r.location
 	}
 	return res, nil
 }
@@ -300,18 +650,27 @@ func (r *Client) GenerateUSSD(
 func (r *Client) FetchUSSDTransaction(
 	reference string,
 ) (*UssdTransactionResponse, error) {
+	return r.FetchUSSDTransactionContext(context.Background(), reference)
+}
+
+//FetchUSSDTransactionContext is like FetchUSSDTransaction but uses ctx for the request
+func (r *Client) FetchUSSDTransactionContext(
+	ctx context.Context,
+	reference string,
+) (*UssdTransactionResponse, error) {
+	ctx = withOperation(ctx, "FetchUSSDTransaction")
 	reqLogger := r.getRequestLogger(map[string]interface{}{
 		"method": "FetchUSSDTransaction",
 		"reference": reference,
 	})
 
-	if err := r.ensureUserIsAuthenticated(); err != nil {
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
 		return nil, err
 	}
 	fetchUssdTransactionUrl := r.generateUrl(baseFetchUssdTransaction, map[string]string{
 		"senderRef": reference,
 	})
-	request, err := r.newGetRequest(fetchUssdTransactionUrl, nil)
+	request, err := r.newGetRequest(ctx, fetchUssdTransactionUrl, nil)
 	if err != nil {
 		reqLogger.WithError(err).Error("could not create get request")
 		return nil, err
@@ -321,6 +680,14 @@ func (r *Client) FetchUSSDTransaction(
 		reqLogger.WithError(err).Error("could not initiate get request")
 		return nil, err
 	}
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.FetchUSSDTransactionContext(retryCtx, reference)
+	}
 	if data == nil {
 		reqLogger.Error("empty response received")
 		return nil, ErrEmptyResponse
@@ -332,7 +699,7 @@ func (r *Client) FetchUSSDTransaction(
 		reqLogger.WithField("status_code", statusCode).
 			WithField("data", string(data)).
 			Error("status code received is not success")
-		return nil, r.toErrorResponse(data)
+		return nil, r.toErrorResponse(statusCode, data)
 	}
 
 	res, err := NewUssdTransactionResponse(data)
@@ -345,28 +712,39 @@ func (r *Client) FetchUSSDTransaction(
 
 	if res != nil {
 		res.UserDefinedReference = reference
+		res.clock = r.clock
+		res.location = r.location
 	}
 	return res, nil
 }
 
-//FetchTransaction retrieves all transactions for the current user
+//FetchTransaction retrieves all transactions for the current user. When
+//options set neither StartDate nor EndDate only the last thirty days are
+//fetched
 func (r *Client) FetchTransaction(options *FetchTransactionOption) ([]WalletTransaction, error) {
+	return r.FetchTransactionContext(context.Background(), options)
+}
+
+//FetchTransactionContext is like FetchTransaction but uses ctx for the request
+func (r *Client) FetchTransactionContext(ctx context.Context, options *FetchTransactionOption) ([]WalletTransaction, error) {
+	ctx = withOperation(ctx, "FetchTransaction")
 	reqLogger := r.getRequestLogger(map[string]interface{}{
 		"method": "FetchTransaction",
 		"options": options,
 	})
 
-	if err := r.ensureUserIsAuthenticated(); err != nil {
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
 		reqLogger.WithError(err).Error("could not ensure user is authenticated")
 		return nil, err
 	}
 
-	queryParams := make(map[string]string)
+	var queryOptions FetchTransactionOption
 	if options != nil {
-		queryParams = options.ToMap()
+		queryOptions = *options
 	}
+	queryParams := queryOptions.withDefaultWindow(r.clock.Now()).ToMap()
 	transactionsUrl := r.generateUrl(baseTransactionsUrl,queryParams)
-	request, err := r.newGetRequest(transactionsUrl, nil)
+	request, err := r.newGetRequest(ctx, transactionsUrl, nil)
 	if err != nil {
 		reqLogger.WithError(err).Error("unable to create get request")
 		return nil, err
@@ -378,224 +756,1646 @@ func (r *Client) FetchTransaction(options *FetchTransactionOption) ([]WalletTran
 		return nil, err
 	}
 
-	if data == nil {
-		return nil, ErrEmptyResponse
+	if r.emptySuccess(statusCode, data) {
+		return []WalletTransaction{}, nil
 	}
 
 	if statusCode == http.StatusForbidden {
-		r.access.reset()
-		return r.FetchTransaction(options)
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.FetchTransactionContext(retryCtx, options)
 	}
 
 	if !r.successCode(statusCode) {
 		reqLogger.WithField("data", string(data)).WithField("status_code",statusCode).Error("status code not success")
-		return nil, r.toErrorResponse(data)
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	transactions, err := NewWalletTransactionsIn(data, r.location)
+	if err != nil {
+		return nil, err
 	}
+	return filterDebit(transactions, queryOptions.Debit), nil
+}
 
-	return NewWalletTransactions(data)
+//FetchAllTransactions walks the transaction pages, using the last tranId of
+//each page as the After cursor, until a page comes back empty or max
+//transactions have been collected. A max of zero or less means no limit
+func (r *Client) FetchAllTransactions(options *FetchTransactionOption, max int) ([]WalletTransaction, error) {
+	return r.FetchAllTransactionsContext(context.Background(), options, max)
 }
 
-func (r *Client) getRequestLogger(params ...map[string]interface{}) *logrus.Entry {
-	vlog := r.logger.WithField("x-log-correlation-id", uuid.NewString())
-	for _, p := range params {
-		vlog = vlog.WithFields(p)
+//FetchAllTransactionsContext is like FetchAllTransactions but uses ctx for the requests
+func (r *Client) FetchAllTransactionsContext(ctx context.Context, options *FetchTransactionOption, max int) ([]WalletTransaction, error) {
+	var pageOptions FetchTransactionOption
+	if options != nil {
+		pageOptions = *options
 	}
-	return vlog
-}
+	pageOptions = pageOptions.withDefaultWindow(r.clock.Now())
 
-func (r *Client) login() error {
+	//pages are fetched unfiltered so the cursor follows the api's order
+	debit := pageOptions.Debit
+	pageOptions.Debit = nil
 
-	authCacheKey := r.makeAuthCacheKeys()
-	authorizationKeyValue, err := r.storage.GetString(authCacheKey.authorizationKey)
-	if err == nil {
-		if authorizationKeyValue != "" {
-			r.access.authorization = authorizationKeyValue
+	var result []WalletTransaction
+	for page := 0; page < maxTransactionPages; page++ {
+		transactions, err := r.FetchTransactionContext(ctx, &pageOptions)
+		if err != nil {
+			return nil, err
 		}
-	}
-	sessionIDKeyValue, err := r.storage.GetString(authCacheKey.sessionIDKey)
-	if err == nil {
-		if sessionIDKeyValue != "" {
-			r.access.sessionID = sessionIDKeyValue
+
+		if len(transactions) == 0 {
+			return result, nil
 		}
-	}
 
-	authEncodedPinValue, err := r.storage.GetString(authCacheKey.encodedPinKey)
-	if err == nil {
-		if authEncodedPinValue != "" {
-			r.access.encodedPin = authEncodedPinValue
+		result = append(result, filterDebit(transactions, debit)...)
+		if max > 0 && len(result) >= max {
+			return result[:max], nil
 		}
-	}
-	authExpirationKeyValue, err := r.storage.GetInt(authCacheKey.expirationKey)
-	if err == nil {
-		if authExpirationKeyValue > 0 {
-			_sessionExpiresAt := time.Unix(authExpirationKeyValue, 0)
-			r.access.expiration = _sessionExpiresAt
+
+		cursor := transactions[len(transactions)-1].TranID
+		if pageOptions.After != nil && *pageOptions.After == cursor {
+			r.logger.WithField("after", cursor).Warn("transaction cursor did not advance, stopping")
+			return result, nil
 		}
+		pageOptions.After = &cursor
 	}
 
-	if !r.access.hasExpired() {
-		return nil
+	r.logger.WithField("pages", maxTransactionPages).Warn("stopped fetching transactions after too many pages")
+	return result, nil
+}
+
+//NameEnquiry resolves the account name for an account number at the given bank
+func (r *Client) NameEnquiry(accountNumber, bankCode string) (*NameEnquiryResponse, error) {
+	return r.NameEnquiryContext(context.Background(), accountNumber, bankCode)
+}
+
+//NameEnquiryContext is like NameEnquiry but uses ctx for the request
+func (r *Client) NameEnquiryContext(ctx context.Context, accountNumber, bankCode string) (*NameEnquiryResponse, error) {
+	ctx = withOperation(ctx, "NameEnquiry")
+	payload := map[string]interface{}{
+		"accountNumber": accountNumber,
+		"bankCode":      bankCode,
 	}
 
-	payload := url.Values{
-		"userName":      {r.account.UserName},
-		"password":      {r.account.Password},
-		"sessionLength": {fmt.Sprintf("%d", int64(r.account.SessionLength.Seconds()))},
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "NameEnquiry",
+	}, payload)
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
 	}
-	loginURL := fmt.Sprintf("%s%s", r.baseURL, baseLoginUrl)
-	res, err := r.httpClient.PostForm(loginURL, payload)
+
+	payloadReader, err := r.fromMapToReader(payload)
 	if err != nil {
-		return err
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
 	}
 
-	if res.Body != nil {
-		defer r.tryCloseBody(res.Body)
+	nameEnquiryUrl := r.generateUrl(baseNameEnquiry)
+	request, err := r.newPostRequest(withRetryMode(ctx, retrySafe), nameEnquiryUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request for name enquiry")
+		return nil, err
 	}
 
-	bodyString, err := ioutil.ReadAll(res.Body)
+	statusCode, data, err := r.doRequest(request)
 	if err != nil {
-		return err
+		reqLogger.WithError(err).Error("encountered error doing post request for name enquiry")
+		return nil, err
 	}
 
-	if !r.successCode(res.StatusCode) {
-		return fmt.Errorf("%s %w", string(bodyString), ErrLoginFailed)
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.NameEnquiryContext(retryCtx, accountNumber, bankCode)
 	}
 
-	r.access.authorization = res.Header.Get("Authorization")
-	r.access.sessionID = res.Header.Get("X-SessionID")
-	if err := r.access.setPin(r.account.Pin, r.access.sessionID); err != nil {
-		return err
+	if data == nil {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
 	}
 
-	r.access.expiration = time.Now().Add(r.account.SessionLength)
+	reqLogger.WithField("response", string(data)).Debug("name enquiry response")
 
-	if err := r.storage.SetStringFor(authCacheKey.authorizationKey, r.access.authorization, r.account.SessionLength); err != nil {
-		return err
-	}
-	if err := r.storage.SetStringFor(authCacheKey.sessionIDKey, r.access.sessionID, r.account.SessionLength); err != nil {
-		return err
-	}
-	if err := r.storage.SetStringFor(authCacheKey.encodedPinKey, r.access.encodedPin, r.account.SessionLength); err != nil {
-		return err
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
 	}
-	if err := r.storage.SetIntFor(authCacheKey.expirationKey, r.access.expiration.Unix(), r.account.SessionLength); err != nil {
-		return err
+
+	res, err := NewNameEnquiryResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating name enquiry model from response")
+		return nil, err
 	}
-	return nil
-}
 
-func (r *Client) successCode(statusCode int) bool {
-	return statusCode == http.StatusOK || statusCode == http.StatusCreated ||statusCode == http.StatusAccepted
+	return res, nil
 }
 
-func (r *Client) makeAuthCacheKeys() authCacheKey {
-	baseCacheKey := fmt.Sprintf("%x", md5.New().Sum([]byte(
-		fmt.Sprintf("%s/%s/%s", r.baseURL, baseLoginUrl, r.account.UserName),
-	)))
-	authorizationKeyName := fmt.Sprintf("%s-auth-token", baseCacheKey)
-	sessionIDKeyName := fmt.Sprintf("%s-session-id", baseCacheKey)
-	authExpirationKeyName := fmt.Sprintf("%s-auth-expiration", baseCacheKey)
-	authEncodedPinKeyName := fmt.Sprintf("%s-auth-encoded-pin", baseCacheKey)
-
-	return authCacheKey{
-		authorizationKeyName,
-		sessionIDKeyName,
-		authExpirationKeyName,
-		authEncodedPinKeyName,
-	}
+//BankFundsTransfer sends money from the wallet to an external bank account
+func (r *Client) BankFundsTransfer(req BankTransferRequest) (*TransferResponse, error) {
+	return r.BankFundsTransferContext(context.Background(), req)
 }
 
-func (r *Client) ensureUserIsAuthenticated() error {
-	if r.hasSessionExpired() {
-		if err := r.login(); err != nil {
-			return err
+//BankFundsTransferContext is like BankFundsTransfer but uses ctx for the request
+func (r *Client) BankFundsTransferContext(ctx context.Context, req BankTransferRequest) (*TransferResponse, error) {
+	if r.shouldAudit(ctx) {
+		res, err := r.BankFundsTransferContext(withAudited(ctx), req)
+		record := AuditRecord{Operation: "BankFundsTransfer", Amount: req.Amount, Reference: req.Reference, Err: err}
+		if res != nil {
+			record.TransactionRef, record.Status = res.TransactionRef, res.Status
 		}
+		r.audit(record)
+		return res, err
 	}
-	return nil
-}
 
-func (r *Client) hasSessionExpired() bool {
-	return r.access.hasExpired()
-}
+	ctx = withOperation(ctx, "BankFundsTransfer")
+	ctx = ensureIdempotencyKey(ctx)
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method":        "BankFundsTransfer",
+		"amount":        req.Amount,
+		"accountNumber": req.AccountNumber,
+		"bankCode":      req.BankCode,
+		"ref":           req.Reference,
+	})
 
-func (r *Client) newGetRequest(url string, body io.Reader) (*http.Request, error) {
-	return r.newRequest("GET",url,body)
-}
+	if err := r.checkAmount(req.Amount); err != nil {
+		reqLogger.WithError(err).Error("amount is not valid")
+		return nil, err
+	}
 
-func (r *Client) newPostRequest(url string, body io.Reader) (*http.Request, error) {
-	return r.newRequest("POST",url,body)
-}
+	if r.dryRun {
+		r.logDryRun(reqLogger, req.toPayload(""))
+		return dryRunTransferResponse(req.Reference, req.Amount), nil
+	}
 
-func (r *Client) newRequest(method, url string, body io.Reader) (*http.Request, error) {
-	var contents string
-	if body != nil {
-		buff := bytes.NewBufferString("")
-		_, _ = io.Copy(buff, body)
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
 	}
-	r.logger.WithField("url", url).
-		WithField("method",method).
-		WithField("body",string(contents)).
-		Debug("new request information")
 
-	req, err := http.NewRequest(method, url,body)
+	payloadReader, err := r.fromMapToReader(req.toPayload(r.session().encodedPin))
 	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
 		return nil, err
 	}
-	r.appendAuthHeaders(req)
-	return req, nil
-}
 
-func (r *Client) appendAuthHeaders(request *http.Request) {
-	request.Header.Add("Authorization", r.access.authorization)
-	request.Header.Add("X-SessionID", r.access.sessionID)
-	request.Header.Add("Content-Type", "application/json")
-}
+	transferUrl := r.generateUrl(baseBankFundsTransferUrl)
+	request, err := r.newPostRequest(withRetryMode(ctx, retryIdempotent), transferUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request for bank transfer")
+		return nil, err
+	}
 
-func (r *Client) tryCloseBody(body io.ReadCloser) {
-	if body != nil {
-		if err := body.Close(); err != nil {
-			r.logger.WithError(err).Error("failed to close response body successfully")
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request for bank transfer")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
 		}
+		return r.BankFundsTransferContext(retryCtx, req)
 	}
-}
 
-func (r *Client) fromMapToReader(payload map[string]interface{}) (io.Reader, error) {
-	payloadBytes, err := json.Marshal(payload)
+	if data == nil {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("bank transfer response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewTransferResponse(data)
 	if err != nil {
-		r.logger.WithError(err).Error("failed to encode ussd payload")
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating transfer model from response")
 		return nil, err
 	}
 
-	r.logger.WithField("payload", string(payloadBytes)).Debug("ussd request payload")
+	if res.Amount == 0 {
+		res.Amount = req.Amount
+	}
+	return res, nil
+}
 
-	return bytes.NewReader(payloadBytes), nil
+//BankFundsTransferWithConfirmation resolves the destination account with
+//NameEnquiry and only makes the transfer when the account name matches
+//expectedName, ignoring case and extra whitespace. ErrNameMismatch is
+//returned otherwise
+func (r *Client) BankFundsTransferWithConfirmation(req BankTransferRequest, expectedName string) (*TransferResponse, error) {
+	return r.BankFundsTransferWithConfirmationContext(context.Background(), req, expectedName)
 }
 
-func (r *Client) doRequest(req *http.Request) (statusCode int, data []byte, err  error) {
-	res, err := r.httpClient.Do(req)
+//BankFundsTransferWithConfirmationContext is like BankFundsTransferWithConfirmation but uses ctx for the requests
+func (r *Client) BankFundsTransferWithConfirmationContext(ctx context.Context, req BankTransferRequest, expectedName string) (*TransferResponse, error) {
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method":        "BankFundsTransferWithConfirmation",
+		"accountNumber": req.AccountNumber,
+		"bankCode":      req.BankCode,
+		"ref":           req.Reference,
+	})
+
+	enquiry, err := r.NameEnquiryContext(ctx, req.AccountNumber, req.BankCode)
 	if err != nil {
-		r.logger.WithError(err).Error("encountered error doing post request to generate ussd")
-		return 0, nil, err
-	}
-	if res.Body != nil {
-		defer r.tryCloseBody(res.Body)
+		reqLogger.WithError(err).Error("could not resolve the account name")
+		return nil, err
 	}
-	if res.Body == nil {
-		return res.StatusCode, nil, nil
+
+	if !namesMatch(enquiry.AccountName, expectedName) {
+		reqLogger.WithField("accountName", enquiry.AccountName).
+			WithField("expectedName", expectedName).
+			Error("account name does not match the expected name")
+		return nil, fmt.Errorf("%w: expected %q, account is %q", ErrNameMismatch, expectedName, enquiry.AccountName)
 	}
-	data, err =  ioutil.ReadAll(res.Body)
-	return res.StatusCode,data, err
+
+	return r.BankFundsTransferContext(ctx, req)
+}
+
+func namesMatch(a, b string) bool {
+	normalize := func(name string) string {
+		return strings.Join(strings.Fields(name), " ")
+	}
+	return normalize(a) != "" && strings.EqualFold(normalize(a), normalize(b))
+}
+
+//WalletFundsTransfer sends money from the wallet to the wallet registered to recipientPhone
+func (r *Client) WalletFundsTransfer(
+	recipientPhone string,
+	amount float64,
+	narration,
+	reference string,
+) (*TransferResponse, error) {
+	return r.WalletFundsTransferContext(context.Background(), recipientPhone, amount, narration, reference)
+}
+
+//WalletFundsTransferContext is like WalletFundsTransfer but uses ctx for the request
+func (r *Client) WalletFundsTransferContext(
+	ctx context.Context,
+	recipientPhone string,
+	amount float64,
+	narration,
+	reference string,
+) (*TransferResponse, error) {
+	if r.shouldAudit(ctx) {
+		res, err := r.WalletFundsTransferContext(withAudited(ctx), recipientPhone, amount, narration, reference)
+		record := AuditRecord{Operation: "WalletFundsTransfer", Amount: amount, Reference: reference, Err: err}
+		if res != nil {
+			record.TransactionRef, record.Status = res.TransactionRef, res.Status
+		}
+		r.audit(record)
+		return res, err
+	}
+
+	ctx = withOperation(ctx, "WalletFundsTransfer")
+	ctx = ensureIdempotencyKey(ctx)
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method":         "WalletFundsTransfer",
+		"amount":         amount,
+		"recipientPhone": recipientPhone,
+		"ref":            reference,
+	})
+
+	normalizedPhone, err := NormalizePhoneNumber(recipientPhone)
+	if err != nil {
+		reqLogger.WithError(err).Error("recipient phone is not valid")
+		return nil, err
+	}
+	recipientPhone = normalizedPhone
+
+	if err := r.checkAmount(amount); err != nil {
+		reqLogger.WithError(err).Error("amount is not valid")
+		return nil, err
+	}
+
+	if r.dryRun {
+		r.logDryRun(reqLogger, r.walletTransferPayload(recipientPhone, amount, narration, reference))
+		return dryRunTransferResponse(reference, amount), nil
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(r.walletTransferPayload(recipientPhone, amount, narration, reference))
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	transferUrl := r.generateUrl(baseWalletFundsTransferUrl)
+	request, err := r.newPostRequest(withRetryMode(ctx, retryIdempotent), transferUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request for wallet transfer")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request for wallet transfer")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.WalletFundsTransferContext(retryCtx, recipientPhone, amount, narration, reference)
+	}
+
+	if data == nil {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("wallet transfer response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewTransferResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating transfer model from response")
+		return nil, err
+	}
+
+	if res.Amount == 0 {
+		res.Amount = amount
+	}
+	return res, nil
+}
+
+func (r *Client) walletTransferPayload(
+	recipientPhone string,
+	amount float64,
+	narration,
+	reference string,
+) map[string]interface{} {
+	return map[string]interface{}{
+		"amount":    amount,
+		"phone":     recipientPhone,
+		"narration": narration,
+		"ref":       reference,
+		"pin":       r.session().encodedPin,
+	}
+}
+
+//PurchaseAirtime buys airtime on the given network for phone, paid from the wallet
+func (r *Client) PurchaseAirtime(
+	phone string,
+	amount float64,
+	network,
+	reference string,
+) (*AirtimeResponse, error) {
+	return r.PurchaseAirtimeContext(context.Background(), phone, amount, network, reference)
+}
+
+//PurchaseAirtimeContext is like PurchaseAirtime but uses ctx for the request
+func (r *Client) PurchaseAirtimeContext(
+	ctx context.Context,
+	phone string,
+	amount float64,
+	network,
+	reference string,
+) (*AirtimeResponse, error) {
+	if r.shouldAudit(ctx) {
+		res, err := r.PurchaseAirtimeContext(withAudited(ctx), phone, amount, network, reference)
+		record := AuditRecord{Operation: "PurchaseAirtime", Amount: amount, Reference: reference, Err: err}
+		if res != nil {
+			record.TransactionRef, record.Status = res.TransactionRef, res.Status
+		}
+		r.audit(record)
+		return res, err
+	}
+
+	ctx = withOperation(ctx, "PurchaseAirtime")
+	ctx = ensureIdempotencyKey(ctx)
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method":  "PurchaseAirtime",
+		"amount":  amount,
+		"phone":   phone,
+		"network": network,
+		"ref":     reference,
+	})
+
+	networkCode, ok := NetworkCodeFor(network)
+	if !ok {
+		reqLogger.Error("network not supported")
+		return nil, ErrNetworkNotSupported
+	}
+
+	normalizedPhone, err := NormalizePhoneNumber(phone)
+	if err != nil {
+		reqLogger.WithError(err).Error("phone is not valid")
+		return nil, err
+	}
+	phone = normalizedPhone
+
+	if err := r.checkAmount(amount); err != nil {
+		reqLogger.WithError(err).Error("amount is not valid")
+		return nil, err
+	}
+
+	if r.dryRun {
+		r.logDryRun(reqLogger, map[string]interface{}{
+			"amount":  amount,
+			"phone":   phone,
+			"network": networkCode,
+			"ref":     reference,
+		})
+		return dryRunAirtimeResponse(reference, amount), nil
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(map[string]interface{}{
+		"amount":  amount,
+		"phone":   phone,
+		"network": networkCode,
+		"ref":     reference,
+		"pin":     r.session().encodedPin,
+	})
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	airtimeUrl := r.generateUrl(baseAirtimeUrl)
+	request, err := r.newPostRequest(withRetryMode(ctx, retryIdempotent), airtimeUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request for airtime purchase")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request for airtime purchase")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.PurchaseAirtimeContext(retryCtx, phone, amount, network, reference)
+	}
+
+	if data == nil {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("airtime purchase response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewAirtimeResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating airtime model from response")
+		return nil, err
+	}
+
+	if res.Amount == 0 {
+		res.Amount = amount
+	}
+	return res, nil
+}
+
+//CreateVirtualAccount generates a virtual bank account that funds the wallet
+func (r *Client) CreateVirtualAccount(req VirtualAccountRequest) (*VirtualAccountResponse, error) {
+	return r.CreateVirtualAccountContext(context.Background(), req)
+}
+
+//CreateVirtualAccountContext is like CreateVirtualAccount but uses ctx for the request
+func (r *Client) CreateVirtualAccountContext(ctx context.Context, req VirtualAccountRequest) (*VirtualAccountResponse, error) {
+	ctx = withOperation(ctx, "CreateVirtualAccount")
+	ctx = ensureIdempotencyKey(ctx)
+	payload := req.toPayload()
+
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method":       "CreateVirtualAccount",
+		"customerName": req.CustomerName,
+		"ref":          req.Reference,
+	})
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(payload)
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	createUrl := r.generateUrl(virtualBankAccountUrl)
+	request, err := r.newPostRequest(withRetryMode(ctx, retryIdempotent), createUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request for virtual account")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request for virtual account")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.CreateVirtualAccountContext(retryCtx, req)
+	}
+
+	if data == nil {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("virtual account creation response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewVirtualAccountResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating virtual account model from response")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+//FetchVirtualAccountTransactions retrieves the transactions paid into a virtual account
+func (r *Client) FetchVirtualAccountTransactions(accountRef string) ([]WalletTransaction, error) {
+	return r.FetchVirtualAccountTransactionsContext(context.Background(), accountRef)
+}
+
+//FetchVirtualAccountTransactionsContext is like FetchVirtualAccountTransactions but uses ctx for the request
+func (r *Client) FetchVirtualAccountTransactionsContext(ctx context.Context, accountRef string) ([]WalletTransaction, error) {
+	ctx = withOperation(ctx, "FetchVirtualAccountTransactions")
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method":     "FetchVirtualAccountTransactions",
+		"accountRef": accountRef,
+	})
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	transactionsUrl := r.generateUrl(virtualBankAccountTransactionsUrl + url.PathEscape(accountRef))
+	request, err := r.newGetRequest(ctx, transactionsUrl, nil)
+	if err != nil {
+		reqLogger.WithError(err).Error("unable to create get request")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("could not initiate get request")
+		return nil, err
+	}
+
+	if r.emptySuccess(statusCode, data) {
+		return []WalletTransaction{}, nil
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.FetchVirtualAccountTransactionsContext(retryCtx, accountRef)
+	}
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("data", string(data)).WithField("status_code", statusCode).Error("status code not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	return NewWalletTransactionsIn(data, r.location)
+}
+
+//CreateAgent onboards a new agent under the current account
+func (r *Client) CreateAgent(req CreateAgentRequest) (*CreateAgentResponse, error) {
+	return r.CreateAgentContext(context.Background(), req)
+}
+
+//CreateAgentContext is like CreateAgent but uses ctx for the request
+func (r *Client) CreateAgentContext(ctx context.Context, req CreateAgentRequest) (*CreateAgentResponse, error) {
+	ctx = withOperation(ctx, "CreateAgent")
+	payload := req.toPayload()
+
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "CreateAgent",
+	}, payload)
+
+	if err := req.validate(); err != nil {
+		reqLogger.WithError(err).Error("agent details are not valid")
+		return nil, err
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(payload)
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	agentUrl := r.generateUrl(createAgentUrl)
+	request, err := r.newPostRequest(ctx, agentUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request to create agent")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request to create agent")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.CreateAgentContext(retryCtx, req)
+	}
+
+	if data == nil {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("agent creation response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewCreateAgentResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating agent model from response")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+//RegisterUser registers an end user and returns the wallet created for them
+func (r *Client) RegisterUser(req RegisterUserRequest) (*RegisterUserResponse, error) {
+	return r.RegisterUserContext(context.Background(), req)
+}
+
+//RegisterUserContext is like RegisterUser but uses ctx for the request
+func (r *Client) RegisterUserContext(ctx context.Context, req RegisterUserRequest) (*RegisterUserResponse, error) {
+	ctx = withOperation(ctx, "RegisterUser")
+	payload := req.toPayload()
+
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "RegisterUser",
+	}, payload)
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(payload)
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	registerUrl := r.generateUrl(createUserUrl)
+	request, err := r.newPostRequest(ctx, registerUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request to register user")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request to register user")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.RegisterUserContext(retryCtx, req)
+	}
+
+	if data == nil {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("user registration response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewRegisterUserResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating user model from response")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+//CheckTransaction retrieves the current status of any transaction by its reference
+func (r *Client) CheckTransaction(transactionRef string) (*TransactionStatusResponse, error) {
+	return r.CheckTransactionContext(context.Background(), transactionRef)
+}
+
+//CheckTransactionContext is like CheckTransaction but uses ctx for the request
+func (r *Client) CheckTransactionContext(ctx context.Context, transactionRef string) (*TransactionStatusResponse, error) {
+	ctx = withOperation(ctx, "CheckTransaction")
+	payload := map[string]interface{}{
+		"ref": transactionRef,
+	}
+
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "CheckTransaction",
+	}, payload)
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(payload)
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	checkTransactionUrl := r.generateUrl(checkTransaction)
+	request, err := r.newPostRequest(withRetryMode(ctx, retrySafe), checkTransactionUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request to check transaction")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request to check transaction")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.CheckTransactionContext(retryCtx, transactionRef)
+	}
+
+	if len(data) == 0 {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("check transaction response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	res, err := NewTransactionStatusResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating transaction status model from response")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+//ListBanks returns the institutions supported by the api.
+//It is a common endpoint so it does not log in first, the session headers
+//are only sent along when the client already holds a session. The list is
+//kept in storage for a day, or as set with WithBanksCacheTTL, use
+//RefreshBanks to get it from the api again
+func (r *Client) ListBanks() ([]Bank, error) {
+	return r.ListBanksContext(context.Background())
+}
+
+//ListBanksContext is like ListBanks but uses ctx for the request
+func (r *Client) ListBanksContext(ctx context.Context) ([]Bank, error) {
+	if banks, ok := r.cachedBanks(ctx); ok {
+		return banks, nil
+	}
+	return r.RefreshBanksContext(ctx)
+}
+
+//fetchBanks gets the institution list from the api
+func (r *Client) fetchBanks(ctx context.Context) ([]Bank, error) {
+	ctx = withOperation(ctx, "ListBanks")
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "ListBanks",
+	})
+
+	banksUrl := r.generateUrl(listBanks)
+	request, err := r.newGetRequest(ctx, banksUrl, nil)
+	if err != nil {
+		reqLogger.WithError(err).Error("unable to create get request")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("could not initiate get request")
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, ErrEmptyResponse
+	}
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("data", string(data)).WithField("status_code", statusCode).Error("status code not success")
+		return nil, r.toErrorResponse(statusCode, data)
+	}
+
+	return NewBanks(data)
+}
+
+//ChangePin changes the transaction pin of the account. On success the
+//client logs in with newPin from then on and the session's encoded pin, in
+//memory and in storage, is re-encrypted from it. The Account the client was
+//created with is left as it was
+func (r *Client) ChangePin(oldPin, newPin string) error {
+	return r.ChangePinContext(context.Background(), oldPin, newPin)
+}
+
+//ChangePinContext is like ChangePin but uses ctx for the request
+func (r *Client) ChangePinContext(ctx context.Context, oldPin, newPin string) error {
+	ctx = withOperation(ctx, "ChangePin")
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "ChangePin",
+	})
+
+	if oldPin == "" || newPin == "" {
+		reqLogger.Error("old and new pin are required")
+		return fmt.Errorf("%w: pin", ErrMissingRequiredField)
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return err
+	}
+
+	session := r.session()
+	encodedOldPin, err := r.pinEncryptor.Encrypt([]byte(oldPin), []byte(session.sessionID))
+	if err != nil {
+		reqLogger.WithError(err).Error("could not encrypt old pin")
+		return err
+	}
+	if err := session.setPin(r.pinEncryptor, newPin, session.sessionID); err != nil {
+		reqLogger.WithError(err).Error("could not encrypt new pin")
+		return err
+	}
+
+	payloadReader, err := r.fromMapToReader(map[string]interface{}{
+		"oldPin": encodedOldPin,
+		"newPin": session.encodedPin,
+	})
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return err
+	}
+
+	pinUrl := r.generateUrl(changePinUrl)
+	request, err := r.newPostRequest(ctx, pinUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request to change pin")
+		return err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request to change pin")
+		return err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return ErrForbiddenAfterRetry
+		}
+		return r.ChangePinContext(retryCtx, oldPin, newPin)
+	}
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return r.toErrorResponse(statusCode, data)
+	}
+
+	current, replaced := r.setPin(newPin, session)
+	if !replaced {
+		return nil
+	}
+	if err := r.storeSession(ctx, current); err != nil {
+		reqLogger.WithError(err).Error("could not store the new encoded pin")
+		return err
+	}
+
+	return nil
+}
+
+//setPin makes newPin the pin used for logins. The encoded pin of session is
+//only taken when the client still uses the session it was made for, a
+//session from a login made since already carries newPin
+func (r *Client) setPin(newPin string, session authParams) (authParams, bool) {
+	r.accessMu.Lock()
+	defer r.accessMu.Unlock()
+	r.pin = newPin
+	if r.access.sessionID != session.sessionID {
+		return r.access, false
+	}
+	r.access.encodedPin = session.encodedPin
+	return r.access, true
 }
 
-func (r *Client) toErrorResponse(data []byte) error {
+//currentPin returns the pin used for logins
+func (r *Client) currentPin() string {
+	r.accessMu.RLock()
+	defer r.accessMu.RUnlock()
+	return r.pin
+}
+
+//Authenticate logs in ahead of the first call, it does nothing while the
+//client holds a session that has not expired and restores the session from
+//storage when one is kept there
+func (r *Client) Authenticate() error {
+	return r.AuthenticateContext(context.Background())
+}
+
+//AuthenticateContext is like Authenticate but uses ctx for the login
+func (r *Client) AuthenticateContext(ctx context.Context) error {
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		r.logger.WithError(err).Error("could not ensure user is authenticated")
+		return err
+	}
+	return nil
+}
+
+//SetToken makes the client use a session obtained outside of it, until
+//expiry. The session is written to storage like one from a login
+func (r *Client) SetToken(auth, sessionID, encodedPin string, expiry time.Time) {
+	session := authParams{
+		authorization: auth,
+		sessionID:     sessionID,
+		encodedPin:    encodedPin,
+		expiration:    expiry,
+	}
+	r.setSession(session)
+	if err := r.storeSession(context.Background(), session); err != nil {
+		r.logger.WithError(err).Warn("could not store session")
+	}
+}
+
+//Logout ends the current session and clears it from storage so the next
+//call logs in again. The api has no logout endpoint, so the session token
+//stays valid on the server until it expires
+func (r *Client) Logout() error {
+	return r.LogoutContext(context.Background())
+}
+
+//LogoutContext is like Logout but uses ctx for the storage calls
+func (r *Client) LogoutContext(ctx context.Context) error {
+	r.resetSession()
+	if err := r.clearCachedSession(ctx); err != nil {
+		r.logger.WithError(err).Error("could not clear cached session")
+		return err
+	}
+	return nil
+}
+
+//LastLoginInfo returns the result of the most recent login made over the
+//network, it is nil when the session was only restored from storage
+func (r *Client) LastLoginInfo() *LoginResult {
+	r.accessMu.RLock()
+	defer r.accessMu.RUnlock()
+	if r.lastLogin == nil {
+		return nil
+	}
+	result := *r.lastLogin
+	return &result
+}
+
+//IsAuthenticated reports whether the client holds a session that has not expired
+func (r *Client) IsAuthenticated() bool {
+	return !r.hasSessionExpired()
+}
+
+//SessionExpiry returns when the current session expires, it is zero before the first login
+func (r *Client) SessionExpiry() time.Time {
+	return r.session().expiration
+}
+
+//SessionExpiresWithin reports whether the current session will have expired
+//d from now, it is true when there is no session. A background job can use
+//it to Logout and Authenticate again before calls start failing
+func (r *Client) SessionExpiresWithin(d time.Duration) bool {
+	return r.session().hasExpired(r.clock.Now().Add(d))
+}
+
+func (r *Client) getRequestLogger(params ...map[string]interface{}) Logger {
+	vlog := r.logger.WithField("x-log-correlation-id", uuid.NewString())
+	for _, p := range params {
+		vlog = vlog.WithFields(p)
+	}
+	return vlog
+}
+
+func (r *Client) loginContext(ctx context.Context) error {
+
+	session, ok := r.cachedSession(ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if ok && !session.hasExpired(r.clock.Now()) {
+		r.setSession(session)
+		return nil
+	}
+
+	if r.disableAutoLogin {
+		return ErrSessionExpired
+	}
+
+	if err := r.recentLoginFailure(); err != nil {
+		return err
+	}
+
+	payload := url.Values{
+		"userName":      {r.account.UserName},
+		"password":      {r.account.Password},
+		"sessionLength": {fmt.Sprintf("%d", int64(r.sessionLength().Seconds()))},
+	}
+	loginURL := fmt.Sprintf("%s%s", r.baseURL, baseLoginUrl)
+	if err := r.waitForRateLimit(ctx); err != nil {
+		return err
+	}
+	reqCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, "POST", loginURL, strings.NewReader(payload.Encode()))
+	if err != nil {
+		return err
+	}
+	r.applyDefaultHeaders(req)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", r.userAgent)
+
+	//allow is only called once nothing but the request itself can fail, the
+	//half open trial it hands out is freed by record
+	if err := r.breaker.allow(r.clock.Now()); err != nil {
+		return err
+	}
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		r.dump.write(req, nil, nil, err)
+		r.breaker.record(ctx, r.clock.Now(), 0, err)
+		if ctxErr := reqCtx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	r.breaker.record(ctx, r.clock.Now(), res.StatusCode, nil)
+
+	if res.Body != nil {
+		defer r.tryCloseBody(res.Body)
+	}
+
+	bodyString, err := r.readBody(res.Body)
+	r.dump.write(req, res, bodyString, err)
+	if err != nil {
+		return err
+	}
+
+	if !r.successCode(res.StatusCode) {
+		err := fmt.Errorf("%s %w", string(bodyString), ErrLoginFailed)
+		r.setLoginFailure(err)
+		return err
+	}
+
+	loginResult, resultErr := NewLoginResult(bodyString)
+	if resultErr != nil {
+		r.logger.WithError(resultErr).Warn("could not decode login response body")
+		loginResult = nil
+	}
+
+	session = authParams{
+		authorization: res.Header.Get("Authorization"),
+		sessionID:     res.Header.Get("X-SessionID"),
+		expiration:    r.clock.Now().Add(r.grantedSessionLength(res.Header, loginResult)),
+	}
+	if err := checkLoginHeaders(session); err != nil {
+		r.logger.WithError(err).WithField("status_code", res.StatusCode).Error("login response is missing session headers")
+		r.setLoginFailure(err)
+		return err
+	}
+	r.setLoginFailure(nil)
+	if err := session.setPin(r.pinEncryptor, r.currentPin(), session.sessionID); err != nil {
+		return err
+	}
+	r.setSession(session)
+
+	var loginInfo LoginResult
+	if loginResult != nil {
+		r.setLastLogin(loginResult)
+		loginInfo = *loginResult
+	}
+
+	if err := r.storeSession(ctx, session); err != nil {
+		return err
+	}
+
+	if r.onLogin != nil {
+		r.onLogin(loginInfo)
+	}
+	return nil
+}
+
+//checkLoginHeaders fails with ErrLoginMissingCredentials when the login
+//response left out the Authorization or X-SessionID header, every call made
+//without them would be forbidden
+func checkLoginHeaders(session authParams) error {
+	var missing []string
+	if session.authorization == "" {
+		missing = append(missing, "Authorization")
+	}
+	if session.sessionID == "" {
+		missing = append(missing, "X-SessionID")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrLoginMissingCredentials, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (r *Client) successCode(statusCode int) bool {
+	return statusCode == http.StatusOK || statusCode == http.StatusCreated ||statusCode == http.StatusAccepted ||
+		statusCode == http.StatusNoContent
+}
+
+//emptySuccess reports whether the api answered with a success status and no
+//body, which the list endpoints do when there is nothing to list
+func (r *Client) emptySuccess(statusCode int, data []byte) bool {
+	return r.successCode(statusCode) && len(bytes.TrimSpace(data)) == 0
+}
+
+//grantedSessionLength is how long the api says the new session lasts,
+//going by the X-Session-Length header or else the session_length field of
+//the body, both in seconds. The api may grant less than was asked for, the
+//requested sessionLength is only used when it does not say
+func (r *Client) grantedSessionLength(header http.Header, result *LoginResult) time.Duration {
+	if seconds, err := strconv.ParseInt(strings.TrimSpace(header.Get(sessionLengthHeader)), 10, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if result != nil && result.SessionLength > 0 {
+		return time.Duration(result.SessionLength) * time.Second
+	}
+	return r.sessionLength()
+}
+
+//sessionLength is the account SessionLength, or defaultSessionLength when
+//it was not set
+func (r *Client) sessionLength() time.Duration {
+	if r.account.SessionLength <= 0 {
+		return defaultSessionLength
+	}
+	return r.account.SessionLength
+}
+
+func (r *Client) makeAuthCacheKeys() authCacheKey {
+	baseCacheKey := fmt.Sprintf("%x", md5.Sum([]byte(
+		fmt.Sprintf("%s/%s/%s", r.baseURL, baseLoginUrl, r.account.UserName),
+	)))
+	return authCacheKey{
+		sessionKey: fmt.Sprintf("%s-auth-session", baseCacheKey),
+	}
+}
+
+//cachedSession reads back the session stored by storeSession, it reports
+//false when there is none or it cannot be decoded
+func (r *Client) cachedSession(ctx context.Context) (authParams, bool) {
+	data, err := r.storage.GetStringContext(ctx, r.makeAuthCacheKeys().sessionKey)
+	if err != nil || data == "" {
+		return authParams{}, false
+	}
+	session, err := unmarshalAuthParams(data)
+	if err != nil {
+		r.logger.WithError(err).Warn("could not decode the cached session")
+		return authParams{}, false
+	}
+	return session, true
+}
+
+//storeSession writes the whole session to storage as a single value
+func (r *Client) storeSession(ctx context.Context, session authParams) error {
+	data, err := session.marshal()
+	if err != nil {
+		return err
+	}
+	ttl := session.expiration.Sub(r.clock.Now())
+	if ttl <= 0 {
+		return nil
+	}
+	return r.storage.SetStringForContext(ctx, r.makeAuthCacheKeys().sessionKey, data, ttl)
+}
+
+//retryAfterForbidden drops the current session so the caller can retry once
+//after logging in again, it reports false when the retry was already used.
+//Callers retry by calling themselves again rather than resending the request,
+//the pin is encoded with the session key so the payload has to be rebuilt
+func (r *Client) retryAfterForbidden(ctx context.Context) (context.Context, bool) {
+	if retried, _ := ctx.Value(forbiddenRetryKey{}).(bool); retried {
+		return ctx, false
+	}
+	r.resetSession()
+	if err := r.clearCachedSession(ctx); err != nil {
+		r.logger.WithError(err).Warn("could not clear cached session")
+	}
+	return context.WithValue(ctx, forbiddenRetryKey{}, true), true
+}
+
+//clearCachedSession deletes the stored session so the next login goes to
+//the api instead of restoring the credentials that were just rejected
+func (r *Client) clearCachedSession(ctx context.Context) error {
+	return r.storage.DeleteContext(ctx, r.makeAuthCacheKeys().sessionKey)
+}
+
+//ensureUserIsAuthenticated logs in when the session has expired. Concurrent
+//callers share a single login, so they all get the result of the first
+//caller's attempt even if their own ctx differs
+func (r *Client) ensureUserIsAuthenticated(ctx context.Context) error {
+	if r.hasSessionExpired() {
+		loginKey := r.makeAuthCacheKeys().sessionKey
+		if err := r.logins.do(loginKey, func() error {
+			return r.loginContext(ctx)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//session returns a copy of the current auth params, safe to read while other
+//goroutines log in or reset the session
+func (r *Client) session() authParams {
+	r.accessMu.RLock()
+	defer r.accessMu.RUnlock()
+	return r.access
+}
+
+func (r *Client) setSession(p authParams) {
+	r.accessMu.Lock()
+	defer r.accessMu.Unlock()
+	r.access = p
+}
+
+//recentLoginFailure returns the error of the last login when it failed less
+//than the WithLoginBackoff interval ago
+func (r *Client) recentLoginFailure() error {
+	r.accessMu.RLock()
+	defer r.accessMu.RUnlock()
+	if r.failedLogin == nil || r.clock.Now().Sub(r.failedLoginAt) >= r.loginBackoff {
+		return nil
+	}
+	return r.failedLogin
+}
+
+//setLoginFailure remembers err as the outcome of the last login, nil clears it
+func (r *Client) setLoginFailure(err error) {
+	r.accessMu.Lock()
+	defer r.accessMu.Unlock()
+	r.failedLogin = err
+	r.failedLoginAt = r.clock.Now()
+}
+
+func (r *Client) setLastLogin(result *LoginResult) {
+	r.accessMu.Lock()
+	defer r.accessMu.Unlock()
+	r.lastLogin = result
+}
+
+func (r *Client) resetSession() {
+	r.accessMu.Lock()
+	defer r.accessMu.Unlock()
+	r.access.reset()
+}
+
+func (r *Client) hasSessionExpired() bool {
+	return r.session().hasExpired(r.clock.Now())
+}
+
+func (r *Client) newGetRequest(ctx context.Context, url string, body io.Reader) (*http.Request, error) {
+	return r.newRequest(ctx, "GET",url,body)
+}
+
+func (r *Client) newPostRequest(ctx context.Context, url string, body io.Reader) (*http.Request, error) {
+	return r.newRequest(ctx, "POST",url,body)
+}
+
+func (r *Client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	var contents []byte
+	if body != nil {
+		var err error
+		if contents, err = ioutil.ReadAll(body); err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(contents)
+	}
+	r.logger.WithField("url", url).
+		WithField("method",method).
+		WithField("body",string(redactBody("application/json", contents))).
+		Debug("new request information")
+
+	req, err := http.NewRequestWithContext(ctx, method, url,body)
+	if err != nil {
+		return nil, err
+	}
+	r.appendAuthHeaders(req)
+	if key := idempotencyKeyFromContext(ctx); key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req, nil
+}
+
+func (r *Client) appendAuthHeaders(request *http.Request) {
+	r.applyDefaultHeaders(request)
+	session := r.session()
+	if session.authorization != "" {
+		request.Header.Add("Authorization", session.authorization)
+	}
+	if session.sessionID != "" {
+		request.Header.Add("X-SessionID", session.sessionID)
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Set("User-Agent", r.userAgent)
+}
+
+//applyDefaultHeaders sets the headers from WithDefaultHeaders, skipping any
+//header the client manages itself
+func (r *Client) applyDefaultHeaders(request *http.Request) {
+	for key, value := range r.defaultHeaders {
+		if _, managed := managedHeaders[http.CanonicalHeaderKey(key)]; managed {
+			continue
+		}
+		request.Header.Set(key, value)
+	}
+}
+
+//withTimeout bounds ctx by the timeout set with WithTimeout, if any
+func (r *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+//readBody reads a response body of at most maxResponseSize bytes, a longer
+//body fails with ErrResponseTooLarge
+func (r *Client) readBody(body io.Reader) ([]byte, error) {
+	limit := r.maxResponseSize
+	if limit <= 0 {
+		limit = defaultMaxResponseSize
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
+
+func (r *Client) tryCloseBody(body io.ReadCloser) {
+	if body != nil {
+		if err := body.Close(); err != nil {
+			r.logger.WithError(err).Error("failed to close response body successfully")
+		}
+	}
+}
+
+func (r *Client) fromMapToReader(payload map[string]interface{}) (io.Reader, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to encode request payload")
+		return nil, err
+	}
+
+	r.logger.WithField("payload", string(redactBody("application/json", payloadBytes))).Debug("request payload")
+
+	return bytes.NewReader(payloadBytes), nil
+}
+
+func (r *Client) doRequest(req *http.Request) (statusCode int, data []byte, err  error) {
+	req, span := r.startSpan(req)
+	defer func() {
+		endSpan(span, statusCode, err)
+	}()
+
+	if err := r.breaker.allow(r.clock.Now()); err != nil {
+		return 0, nil, err
+	}
+	defer func(ctx context.Context) {
+		r.breaker.record(ctx, r.clock.Now(), statusCode, err)
+	}(req.Context())
+
+	if !r.isRetryable(req) {
+		statusCode, _, data, err = r.doObservedRequest(req)
+		return statusCode, data, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		var header http.Header
+		statusCode, header, data, err = r.doObservedRequest(req)
+		if attempt >= r.retryPolicy.MaxRetries || !r.shouldRetry(req, statusCode, err) {
+			return statusCode, data, err
+		}
+
+		delay, ok := r.retryDelay(attempt, statusCode, header)
+		if !ok {
+			return statusCode, data, err
+		}
+		r.logger.WithField("url", req.URL.String()).
+			WithField("operation", operationFromContext(req.Context())).
+			WithField("status_code", statusCode).
+			WithField("attempt", attempt+1).
+			WithField("delay", delay.String()).
+			Warn("retrying failed request")
+
+		if err := r.waitForRetry(req.Context(), delay); err != nil {
+			return 0, nil, err
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+//doObservedRequest makes a single attempt and reports it to the observer
+func (r *Client) doObservedRequest(req *http.Request) (statusCode int, header http.Header, data []byte, err error) {
+	if err := r.waitForRateLimit(req.Context()); err != nil {
+		return 0, nil, nil, err
+	}
+
+	start := r.clock.Now()
+	statusCode, header, data, err = r.doRequestOnce(req)
+	r.observer.ObserveRequest(operationFromContext(req.Context()), statusCode, r.clock.Now().Sub(start), err)
+	return statusCode, header, data, err
+}
+
+func (r *Client) doRequestOnce(req *http.Request) (statusCode int, header http.Header, data []byte, err  error) {
+	ctx, cancel := r.withTimeout(req.Context())
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		r.logger.WithError(err).
+			WithField("operation", operationFromContext(req.Context())).
+			WithField("method", req.Method).
+			WithField("url", req.URL.String()).
+			Error("encountered error doing request")
+		r.dump.write(req, nil, nil, err)
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return 0, nil, nil, ctxErr
+		}
+		return 0, nil, nil, err
+	}
+	if res.Body != nil {
+		defer r.tryCloseBody(res.Body)
+	}
+	if res.Body == nil {
+		recordRawResponse(req.Context(), res, nil)
+		r.dump.write(req, res, nil, nil)
+		return res.StatusCode, res.Header, nil, nil
+	}
+	data, err =  r.readBody(res.Body)
+	recordRawResponse(req.Context(), res, data)
+	r.dump.write(req, res, data, err)
+	return res.StatusCode, res.Header, data, err
+}
+
+//toErrorResponse decodes an api error body, bodies that are not json (plain
+//text from proxies and gateways) are wrapped with the http status instead
+func (r *Client) toErrorResponse(statusCode int, data []byte) error {
 	var e ErrorResponse
 	err := json.Unmarshal(data, &e)
 	if err != nil {
-		return err
+		r.logger.WithError(err).WithField("status_code", statusCode).Debug("error response body is not json")
+		message := strings.TrimSpace(string(data))
+		if message == "" {
+			message = http.StatusText(statusCode)
+		}
+		errResponse := NewErrorResponse(statusCode, statusCode, message, "")
+		errResponse.HTTPStatus = statusCode
+		errResponse.RawBody = data
+		return errResponse
 	}
+	e.HTTPStatus = statusCode
+	e.RawBody = data
 	return &e
 }
 
+//normalizeBaseURL drops surrounding whitespace and trailing slashes so
+//joining it with the api paths, which start with a slash, does not give "//"
+func normalizeBaseURL(baseUrl string) string {
+	return strings.TrimRight(strings.TrimSpace(baseUrl), "/")
+}
+
 func (r *Client) generateUrl(path string, queryParams ...map[string]string) string {
 	reqUri, _ := url.Parse(fmt.Sprintf("%s%s", r.baseURL, path))
 	queryVals, _ := url.ParseQuery(reqUri.RawQuery)
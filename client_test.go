@@ -1,11 +1,16 @@
 package readycash
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -75,6 +80,13 @@ func (m *mockStore) GetInt(key string) (int64, error) {
 	return 0, fmt.Errorf("not a number")
 }
 
+func (m *mockStore) Delete(key string) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
 func TestBalanceEnquiry(t *testing.T)  {
 
 	balanceRes := BalanceEnquiryResponse{
@@ -522,7 +534,7 @@ func TestFetchTransactions(t *testing.T)  {
 			return
 		}
 
-		if req.URL.String() == baseTransactionsUrl {
+		if req.URL.Path == baseTransactionsUrl && req.Form.Get("trantype") == "" {
 			testResults.generateUssdCalled = true
 			rw.Header().Add("content-type","application/json")
 			rw.WriteHeader(http.StatusOK)
@@ -530,7 +542,7 @@ func TestFetchTransactions(t *testing.T)  {
 			return
 		}
 
-		if req.URL.String() == fmt.Sprintf("%s?trantype=%s",baseTransactionsUrl,*transTypeOptions.TranType) {
+		if req.URL.Path == baseTransactionsUrl && req.Form.Get("trantype") == *transTypeOptions.TranType && req.Form.Get("after") == "" {
 			testResults.generateUssdCalled = true
 			rw.Header().Add("content-type","application/json")
 			rw.WriteHeader(http.StatusOK)
@@ -597,4 +609,2209 @@ func intAddr(f int64) *int64 {
 	s := f
 	return &s
 }
+func boolAddr(f bool) *bool {
+	s := f
+	return &s
+}
+
+
+func TestNameEnquiry(t *testing.T) {
+
+	accountNumber := "0123456789"
+	bankCode := "058"
+	accountName := "JOHN DOE"
+
+	sampleResponse := fmt.Sprintf(`{
+		"accountName": "%s",
+		"accountNumber": "%s",
+		"bankCode": "%s"
+	}`, accountName, accountNumber, bankCode)
+
+	testResults := struct {
+		requestCounter    int
+		loginCalled       bool
+		nameEnquiryCalled bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseNameEnquiry && req.Method == http.MethodPost {
+			testResults.nameEnquiryCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.NameEnquiry(accountNumber, bankCode)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.nameEnquiryCalled)
+	assert.Equal(t, accountName, resp.AccountName)
+	assert.Equal(t, accountNumber, resp.AccountNumber)
+	assert.Equal(t, bankCode, resp.BankCode)
+}
+
+func newTestAccount() Account {
+	return Account{
+		UserName:      "sample",
+		Password:      "password",
+		Pin:           "1234",
+		SessionLength: time.Second * 3600,
+	}
+}
+
+func writeTestLoginResponse(rw http.ResponseWriter) {
+	rw.Header().Add("content-type", "application/json")
+	rw.Header().Add("Authorization", "Bearer Token")
+	rw.Header().Add("X-SessionID", "1234")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write([]byte(`{}`))
+}
+
+func TestBankFundsTransfer(t *testing.T) {
+
+	transferRequest := BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Narration:     "school fees",
+		Reference:     "user-defined-ref",
+	}
+
+	sampleResponse := `{
+		"transactionRef": "0000000000001070108",
+		"status": "SUCCESSFUL",
+		"fee": 52.5
+	}`
+
+	testResults := struct {
+		requestCounter int
+		loginCalled    bool
+		transferCalled bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl && req.Method == http.MethodPost {
+			testResults.transferCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.BankFundsTransfer(transferRequest)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.transferCalled)
+	assert.Equal(t, "0000000000001070108", resp.TransactionRef)
+	assert.Equal(t, "SUCCESSFUL", resp.Status)
+	assert.Equal(t, 52.5, resp.Fee)
+	assert.Equal(t, float64(2500), resp.Amount)
+	assert.Equal(t, 2447.5, resp.NetAmount())
+
+	transferRequest.Amount = 0
+	_, err = apiClient.BankFundsTransfer(transferRequest)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	assert.Equal(t, 2, testResults.requestCounter)
+}
+
+func TestWalletFundsTransfer(t *testing.T) {
+
+	recipientPhone := "08031234567"
+	amount := float64(1500)
+
+	sampleResponse := `{
+		"transactionRef": "0000000000001070109",
+		"status": "SUCCESSFUL",
+		"fee": 0
+	}`
+
+	testResults := struct {
+		requestCounter int
+		loginCalled    bool
+		transferCalled bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseWalletFundsTransferUrl && req.Method == http.MethodPost {
+			testResults.transferCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.WalletFundsTransfer(recipientPhone, amount, "lunch", "user-defined-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.transferCalled)
+	assert.Equal(t, "0000000000001070109", resp.TransactionRef)
+	assert.Equal(t, "SUCCESSFUL", resp.Status)
+
+	expectedPin, err := DesEncrypt([]byte(testAccount.Pin), []byte("1234"))
+	if err != nil {
+		t.Fatalf("Did not expect pin encryption to fail: %v", err)
+	}
+	payload := apiClient.walletTransferPayload(recipientPhone, amount, "lunch", "user-defined-ref")
+	assert.Equal(t, expectedPin, payload["pin"])
+
+	for _, phone := range []string{"", "0803123", "12345678901", "+1 202 555 0100"} {
+		_, err = apiClient.WalletFundsTransfer(phone, amount, "lunch", "user-defined-ref")
+		assert.ErrorIs(t, err, ErrInvalidRecipientPhone)
+	}
+	assert.Equal(t, 2, testResults.requestCounter)
+}
+
+func TestPurchaseAirtime(t *testing.T) {
+
+	sampleResponse := `{
+		"transactionRef": "0000000000001070110",
+		"status": "SUCCESSFUL",
+		"amount": 200
+	}`
+
+	testResults := struct {
+		requestCounter int
+		loginCalled    bool
+		airtimeCalled  bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseAirtimeUrl && req.Method == http.MethodPost {
+			testResults.airtimeCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.PurchaseAirtime("08031234567", 200, "MTN", "user-defined-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.airtimeCalled)
+	assert.Equal(t, "0000000000001070110", resp.TransactionRef)
+	assert.Equal(t, "SUCCESSFUL", resp.Status)
+	assert.Equal(t, float64(200), resp.Amount)
+
+	sampleResponse = `{
+		"transactionRef": "0000000000001070111",
+		"status": "SUCCESSFUL",
+		"fee": 5
+	}`
+	resp, err = apiClient.PurchaseAirtime("08031234567", 300, "MTN", "another-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, float64(300), resp.Amount)
+	assert.Equal(t, float64(295), resp.NetAmount())
+}
+
+func TestPurchaseAirtimeUnsupportedNetwork(t *testing.T) {
+
+	requestCounter := 0
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestCounter += 1
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.PurchaseAirtime("08031234567", 200, "VODAFONE", "user-defined-ref")
+	assert.ErrorIs(t, err, ErrNetworkNotSupported)
+	assert.Equal(t, 0, requestCounter)
+}
+
+func TestCreateVirtualAccount(t *testing.T) {
+
+	expiry := time.Now().Add(24 * time.Hour)
+	virtualAccountRequest := VirtualAccountRequest{
+		CustomerName: "John Doe",
+		BVN:          "22222222222",
+		Reference:    "user-defined-ref",
+		Expiry:       &expiry,
+	}
+
+	sampleResponse := `{
+		"accountNumber": "9912345678",
+		"bankName": "PROVIDUS BANK",
+		"accountReference": "user-defined-ref"
+	}`
+
+	testResults := struct {
+		requestCounter       int
+		loginCalled          bool
+		virtualAccountCalled bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == virtualBankAccountUrl && req.Method == http.MethodPost {
+			testResults.virtualAccountCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.CreateVirtualAccount(virtualAccountRequest)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.virtualAccountCalled)
+	assert.Equal(t, "9912345678", resp.AccountNumber)
+	assert.Equal(t, "PROVIDUS BANK", resp.BankName)
+	assert.Equal(t, "user-defined-ref", resp.AccountReference)
+
+	payload := virtualAccountRequest.toPayload()
+	assert.Equal(t, expiry.Unix()*1000, payload["expiry"])
+
+	virtualAccountRequest.Expiry = nil
+	assert.NotContains(t, virtualAccountRequest.toPayload(), "expiry")
+}
+
+func TestFetchVirtualAccountTransactions(t *testing.T) {
+
+	accountRef := "ref/with special?chars"
+
+	sampleResponse := `[
+    {
+        "debit": false,
+        "tranId": 111111112,
+        "tranType": "200.21.0002",
+        "description": "Virtual Account Deposit",
+        "shortDescription": "Virtual Account Deposit",
+        "longDescription": "Virtual Account Deposit",
+        "date": 1622307120000,
+        "amount": 5000.00,
+        "reciept": {
+            "amount": 5000,
+            "date": 1622307120000,
+            "reference": "11112"
+        },
+        "balance": 19324.68,
+        "narration": "VA/9912345678"
+    }]`
+
+	testResults := struct {
+		requestCounter     int
+		loginCalled        bool
+		transactionsCalled bool
+		receivedUrl        string
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if strings.HasPrefix(req.URL.Path, virtualBankAccountTransactionsUrl) {
+			testResults.transactionsCalled = true
+			testResults.receivedUrl = req.URL.String()
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
 
+	resp, err := apiClient.FetchVirtualAccountTransactions(accountRef)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.transactionsCalled)
+	assert.Equal(t, virtualBankAccountTransactionsUrl+"ref%2Fwith%20special%3Fchars", testResults.receivedUrl)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, int64(111111112), resp[0].TranID)
+}
+
+func TestCreateAgent(t *testing.T) {
+
+	agentRequest := CreateAgentRequest{
+		Name:    "Jane Doe",
+		Phone:   "08031234567",
+		Email:   "jane@example.com",
+		Address: "1 Marina, Lagos",
+	}
+
+	sampleResponse := `{
+		"agentId": "AG-1001",
+		"status": "ACTIVE"
+	}`
+
+	testResults := struct {
+		requestCounter    int
+		loginCalled       bool
+		createAgentCalled bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == createAgentUrl && req.Method == http.MethodPost {
+			testResults.createAgentCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.CreateAgent(agentRequest)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.createAgentCalled)
+	assert.Equal(t, "AG-1001", resp.AgentID)
+	assert.Equal(t, "ACTIVE", resp.Status)
+}
+
+func TestCreateAgentValidation(t *testing.T) {
+
+	requestCounter := 0
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestCounter += 1
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.CreateAgent(CreateAgentRequest{
+		Name:    "Jane Doe",
+		Phone:   "08031234567",
+		Address: "1 Marina, Lagos",
+	})
+	assert.ErrorIs(t, err, ErrMissingRequiredField)
+	assert.Contains(t, err.Error(), "email")
+	assert.Equal(t, 0, requestCounter)
+}
+
+func TestRegisterUser(t *testing.T) {
+
+	userRequest := RegisterUserRequest{
+		Phone:     "08031234567",
+		FirstName: "John",
+		LastName:  "Doe",
+	}
+
+	sampleResponse := `{
+		"walletId": "08031234567",
+		"phone": "08031234567",
+		"status": "ACTIVE"
+	}`
+
+	testResults := struct {
+		requestCounter     int
+		loginCalled        bool
+		registerUserCalled bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == createUserUrl && req.Method == http.MethodPost {
+			testResults.registerUserCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.RegisterUser(userRequest)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.registerUserCalled)
+	assert.Equal(t, "08031234567", resp.WalletID)
+	assert.Equal(t, "ACTIVE", resp.Status)
+	assert.NotContains(t, userRequest.toPayload(), "email")
+}
+
+func TestCheckTransaction(t *testing.T) {
+
+	transactionRef := "0000000000001070108"
+
+	sampleResponse := fmt.Sprintf(`{
+		"transactionRef": "%s",
+		"tranType": "200.21.0001",
+		"amount": 992.00,
+		"responseCode": "00",
+		"status": "SUCCESSFUL",
+		"transactionDate": 1622307059000,
+		"completionDate": 1622307102000
+	}`, transactionRef)
+
+	testResults := struct {
+		requestCounter         int
+		loginCalled            bool
+		checkTransactionCalled bool
+		emptyBody              bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == checkTransaction && req.Method == http.MethodPost {
+			testResults.checkTransactionCalled = true
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			if !testResults.emptyBody {
+				rw.Write([]byte(sampleResponse))
+			}
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.CheckTransaction(transactionRef)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 2, testResults.requestCounter)
+	assert.True(t, testResults.loginCalled)
+	assert.True(t, testResults.checkTransactionCalled)
+	assert.Equal(t, transactionRef, resp.TransactionRef)
+	assert.Equal(t, "SUCCESSFUL", resp.Status)
+	assert.Equal(t, float64(992), resp.Amount)
+	assert.Equal(t, int64(1622307102000), resp.CompletionDate)
+
+	testResults.emptyBody = true
+	_, err = apiClient.CheckTransaction(transactionRef)
+	assert.Equal(t, ErrEmptyResponse, err)
+}
+
+func TestListBanks(t *testing.T) {
+
+	sampleResponse := `[
+		{"code": "044", "name": "ACCESS BANK PLC"},
+		{"code": "058", "name": "GUARANTY TRUST BANK PLC"},
+		{"code": "057", "name": "ZENITH BANK PLC"}
+	]`
+
+	testResults := struct {
+		requestCounter   int
+		loginCalled      bool
+		listBanksCalled  bool
+		authHeaderExists bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalled = true
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == listBanks {
+			testResults.listBanksCalled = true
+			_, testResults.authHeaderExists = req.Header["Authorization"]
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(sampleResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.ListBanks()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, 1, testResults.requestCounter)
+	assert.False(t, testResults.loginCalled)
+	assert.True(t, testResults.listBanksCalled)
+	assert.False(t, testResults.authHeaderExists)
+	assert.Len(t, resp, 3)
+	assert.Equal(t, Bank{Code: "058", Name: "GUARANTY TRUST BANK PLC"}, resp[1])
+}
+
+func TestBalanceEnquiryContextCancellation(t *testing.T) {
+
+	testAccount := newTestAccount()
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			select {
+			case <-req.Context().Done():
+			case <-release:
+			}
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "0"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = apiClient.BalanceEnquiryContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	cancelledCtx, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+
+	_, err = apiClient.FetchTransactionContext(cancelledCtx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoginContextCancellation(t *testing.T) {
+
+	testAccount := newTestAccount()
+	release := make(chan struct{})
+	balanceCalled := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			select {
+			case <-req.Context().Done():
+			case <-release:
+			}
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			balanceCalled = true
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = apiClient.BalanceEnquiryContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, balanceCalled)
+	assert.True(t, apiClient.hasSessionExpired())
+}
+
+func TestUSSDOperationsRetryOnceOnForbidden(t *testing.T) {
+
+	ussdResponse := `{
+		"merchantRef": "0000000000011715",
+		"transactionRef": "0000000000001070108",
+		"ussdString": "*901*000*1111#",
+		"amount": 1000,
+		"responseCode": "09",
+		"status": "AWAITING CUSTOMER"
+	}`
+	forbiddenResponse := `{"Status": 403, "Code": 403, "Message": "session expired"}`
+
+	testResults := struct {
+		loginCounter   int
+		ussdCounter    int
+		forbiddenFirst int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCounter += 1
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.Path == baseUssdTransaction || req.URL.Path == baseFetchUssdTransaction {
+			testResults.ussdCounter += 1
+			if testResults.ussdCounter <= testResults.forbiddenFirst {
+				rw.WriteHeader(http.StatusForbidden)
+				rw.Write([]byte(forbiddenResponse))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(ussdResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	testResults.forbiddenFirst = 1
+	resp, err := apiClient.GenerateUSSD("user-defined-ref", 1000, "044")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "0000000000011715", resp.MerchantRef)
+	assert.Equal(t, 2, testResults.loginCounter)
+	assert.Equal(t, 2, testResults.ussdCounter)
+
+	testResults.loginCounter, testResults.ussdCounter = 0, 0
+	resp, err = apiClient.FetchUSSDTransaction("user-defined-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "user-defined-ref", resp.UserDefinedReference)
+	assert.Equal(t, 1, testResults.loginCounter)
+	assert.Equal(t, 2, testResults.ussdCounter)
+
+	testResults.loginCounter, testResults.ussdCounter = 0, 0
+	testResults.forbiddenFirst = 100
+	_, err = apiClient.FetchUSSDTransaction("user-defined-ref")
+	assert.ErrorIs(t, err, ErrForbiddenAfterRetry)
+	assert.Equal(t, 1, testResults.loginCounter)
+	assert.Equal(t, 2, testResults.ussdCounter)
+}
+
+func TestTransfersReencryptPinAfterForbidden(t *testing.T) {
+
+	transferResponse := `{
+		"transactionRef": "0000000000001070109",
+		"status": "SUCCESSFUL",
+		"amount": 2500
+	}`
+
+	testResults := struct {
+		loginCounter    int
+		transferCounter int
+		pins            []string
+		sessionIDs      []string
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCounter += 1
+			rw.Header().Add("content-type", "application/json")
+			rw.Header().Add("Authorization", "Bearer Token")
+			rw.Header().Add("X-SessionID", fmt.Sprintf("session-%d", testResults.loginCounter))
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{}`))
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl || req.URL.String() == baseWalletFundsTransferUrl {
+			var payload map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&payload)
+			testResults.transferCounter += 1
+			testResults.pins = append(testResults.pins, fmt.Sprint(payload["pin"]))
+			testResults.sessionIDs = append(testResults.sessionIDs, req.Header.Get("X-SessionID"))
+			if testResults.transferCounter == 1 {
+				rw.WriteHeader(http.StatusForbidden)
+				rw.Write([]byte(`{"Status": 403, "Code": 403, "Message": "session expired"}`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(transferResponse))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	transfers := map[string]func(*Client) error{
+		"bank": func(apiClient *Client) error {
+			_, err := apiClient.BankFundsTransfer(BankTransferRequest{
+				Amount:        2500,
+				AccountNumber: "0123456789",
+				BankCode:      "044",
+				Narration:     "rent",
+				Reference:     "user-defined-ref",
+			})
+			return err
+		},
+		"wallet": func(apiClient *Client) error {
+			_, err := apiClient.WalletFundsTransfer("08031234567", 2500, "lunch", "user-defined-ref")
+			return err
+		},
+	}
+
+	for name, transfer := range transfers {
+		t.Run(name, func(t *testing.T) {
+			testResults.loginCounter, testResults.transferCounter = 0, 0
+			testResults.pins, testResults.sessionIDs = nil, nil
+
+			apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+			if err != nil {
+				t.Fatalf("Did not expect client creation to fail: %v", err)
+			}
+
+			if err := transfer(apiClient); err != nil {
+				t.Fatalf("Did not expect call to fail: %v", err)
+			}
+			assert.Equal(t, 2, testResults.loginCounter)
+			assert.Equal(t, []string{"session-1", "session-2"}, testResults.sessionIDs)
+
+			stalePin, _ := DesEncrypt([]byte(testAccount.Pin), []byte("session-1"))
+			freshPin, _ := DesEncrypt([]byte(testAccount.Pin), []byte("session-2"))
+			assert.NotEqual(t, stalePin, freshPin)
+			assert.Equal(t, []string{stalePin, freshPin}, testResults.pins)
+		})
+	}
+}
+
+func TestAlwaysForbiddenFailsAfterSingleRetry(t *testing.T) {
+
+	testResults := struct {
+		loginCounter     int
+		forbiddenCounter int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCounter += 1
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		testResults.forbiddenCounter += 1
+		rw.WriteHeader(http.StatusForbidden)
+		rw.Write([]byte(`{"Status": 403, "Code": 403, "Message": "forbidden"}`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		_, err := apiClient.BalanceEnquiry()
+		assert.ErrorIs(t, err, ErrForbiddenAfterRetry)
+		assert.Equal(t, 2, testResults.loginCounter)
+		assert.Equal(t, 2, testResults.forbiddenCounter)
+
+		testResults.loginCounter, testResults.forbiddenCounter = 0, 0
+		_, err = apiClient.FetchTransaction(nil)
+		assert.ErrorIs(t, err, ErrForbiddenAfterRetry)
+		assert.Equal(t, 1, testResults.loginCounter)
+		assert.Equal(t, 2, testResults.forbiddenCounter)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected forbidden responses to fail instead of retrying forever")
+	}
+}
+
+func TestConcurrentRequestsShareSessionSafely(t *testing.T) {
+
+	var balanceCounter int32
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseUssdTransaction {
+			if atomic.AddInt32(&balanceCounter, 1)%5 == 0 {
+				rw.WriteHeader(http.StatusForbidden)
+				rw.Write([]byte(`{"Status": 403, "Code": 403, "Message": "session expired"}`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"merchantRef": "0000000000011715", "status": "AWAITING CUSTOMER"}`))
+			return
+		}
+
+		if req.URL.Path == baseTransactionsUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`[]`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			apiClient.GenerateUSSD("user-defined-ref", 1000, "044")
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := apiClient.FetchTransaction(nil); err != nil {
+				t.Errorf("Did not expect call to fail: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLogout(t *testing.T) {
+
+	loginCounter := 0
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			loginCounter += 1
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "5000","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	mockStoreInstance := NewMockStore()
+	apiClient, err := NewClient(&testAccount, server.URL, mockStoreInstance, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BalanceEnquiry()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 1, loginCounter)
+
+	cached, ok := apiClient.cachedSession(context.Background())
+	assert.True(t, ok)
+	assert.Equal(t, "Bearer Token", cached.authorization)
+
+	assert.NoError(t, apiClient.Logout())
+	assert.True(t, apiClient.hasSessionExpired())
+
+	_, err = mockStoreInstance.GetString(apiClient.makeAuthCacheKeys().sessionKey)
+	assert.Error(t, err)
+
+	_, err = apiClient.BalanceEnquiry()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, loginCounter)
+}
+
+func TestSessionState(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "5000","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	assert.False(t, apiClient.IsAuthenticated())
+	assert.True(t, apiClient.SessionExpiry().IsZero())
+
+	loginStartedAt := time.Now()
+	_, err = apiClient.BalanceEnquiry()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.True(t, apiClient.IsAuthenticated())
+	assert.WithinDuration(t, loginStartedAt.Add(testAccount.SessionLength), apiClient.SessionExpiry(), time.Second)
+
+	session := apiClient.session()
+	session.expiration = time.Now().Add(-time.Minute)
+	apiClient.setSession(session)
+
+	assert.False(t, apiClient.IsAuthenticated())
+	assert.True(t, apiClient.SessionExpiry().Before(time.Now()))
+}
+
+func TestNonJSONErrorResponse(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.FetchTransaction(nil)
+	var errorResponse *ErrorResponse
+	if assert.ErrorAs(t, err, &errorResponse) {
+		assert.Equal(t, http.StatusBadRequest, errorResponse.Status)
+		assert.Equal(t, "OK", errorResponse.Message)
+	}
+
+	_, err = apiClient.BalanceEnquiry()
+	if assert.ErrorAs(t, err, &errorResponse) {
+		assert.Equal(t, http.StatusBadGateway, errorResponse.Status)
+		assert.Equal(t, http.StatusText(http.StatusBadGateway), errorResponse.Message)
+	}
+}
+
+func TestErrorResponseKeepsHTTPStatus(t *testing.T) {
+
+	errorBody := `{"Status": 400, "Code": 12, "Message": "invalid account"}`
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.Header().Add("content-type", "application/json")
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+		rw.Write([]byte(errorBody))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BalanceEnquiry()
+	var errorResponse *ErrorResponse
+	if assert.ErrorAs(t, err, &errorResponse) {
+		assert.Equal(t, http.StatusUnprocessableEntity, errorResponse.HTTPStatus)
+		assert.Equal(t, 400, errorResponse.Status)
+		assert.Equal(t, 12, errorResponse.Code)
+		assert.Equal(t, errorBody, string(errorResponse.RawBody))
+	}
+
+	assert.ErrorIs(t, err, &ErrorResponse{HTTPStatus: http.StatusUnprocessableEntity})
+	assert.ErrorIs(t, err, &ErrorResponse{HTTPStatus: http.StatusUnprocessableEntity, Code: 12})
+	assert.NotErrorIs(t, err, &ErrorResponse{HTTPStatus: http.StatusUnauthorized})
+	assert.NotErrorIs(t, err, &ErrorResponse{Code: 13})
+	assert.NotErrorIs(t, err, ErrEmptyResponse)
+}
+
+func TestPostBodyReachesServer(t *testing.T) {
+
+	testAccount := newTestAccount()
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		received = nil
+		if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte(`{"Status": 400, "Code": 400, "Message": "invalid body"}`))
+			return
+		}
+
+		rw.Header().Add("content-type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"transactionRef": "0000000000001070108", "status": "SUCCESSFUL"}`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetLogLevel(Debug)
+
+	_, err = apiClient.GenerateUSSD("user-defined-ref", 1000, "044")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "user-defined-ref", received["ref"])
+	assert.Equal(t, "044", received["bankCode"])
+	assert.Equal(t, float64(1000), received["amount"])
+
+	_, err = apiClient.WalletFundsTransfer("08031234567", 1500, "lunch", "wallet-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	expectedPin, _ := DesEncrypt([]byte(testAccount.Pin), []byte("1234"))
+	assert.Equal(t, "08031234567", received["phone"])
+	assert.Equal(t, expectedPin, received["pin"])
+}
+
+func TestFetchAllTransactions(t *testing.T) {
+
+	pages := map[string]string{
+		"":   `[{"tranId": 30, "tranType": "200.21.0001", "amount": 100}, {"tranId": 29, "tranType": "200.21.0001", "amount": 200}]`,
+		"29": `[{"tranId": 28, "tranType": "200.21.0001", "amount": 300}, {"tranId": 27, "tranType": "200.21.0001", "amount": 400}]`,
+		"27": `[{"tranId": 26, "tranType": "200.21.0001", "amount": 500}]`,
+		"26": `[]`,
+	}
+
+	testResults := struct {
+		pageRequests []string
+		tranTypes    []string
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.Path == baseTransactionsUrl {
+			after := req.URL.Query().Get("after")
+			testResults.pageRequests = append(testResults.pageRequests, after)
+			testResults.tranTypes = append(testResults.tranTypes, req.URL.Query().Get("trantype"))
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(pages[after]))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	options := FetchTransactionOption{TranType: stringAddr("200.21.0001")}
+	transactions, err := apiClient.FetchAllTransactions(&options, 0)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Len(t, transactions, 5)
+	assert.Equal(t, int64(30), transactions[0].TranID)
+	assert.Equal(t, int64(26), transactions[4].TranID)
+	assert.Equal(t, []string{"", "29", "27", "26"}, testResults.pageRequests)
+	assert.Equal(t, []string{"200.21.0001", "200.21.0001", "200.21.0001", "200.21.0001"}, testResults.tranTypes)
+	assert.Nil(t, options.After)
+
+	testResults.pageRequests = nil
+	transactions, err = apiClient.FetchAllTransactions(nil, 3)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Len(t, transactions, 3)
+	assert.Equal(t, []string{"", "29"}, testResults.pageRequests)
+
+	pages["27"] = `[{"tranId": 27, "tranType": "200.21.0001", "amount": 400}]`
+	testResults.pageRequests = nil
+	transactions, err = apiClient.FetchAllTransactions(nil, 0)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Len(t, transactions, 5)
+	assert.Equal(t, []string{"", "29", "27"}, testResults.pageRequests)
+}
+
+func TestLastLoginInfo(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.Header().Add("Authorization", "Bearer Token")
+			rw.Header().Add("X-SessionID", "1234")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"first_time": true}`))
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	store := NewMockStore()
+	apiClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.Nil(t, apiClient.LastLoginInfo())
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	loginInfo := apiClient.LastLoginInfo()
+	if assert.NotNil(t, loginInfo) {
+		assert.True(t, loginInfo.FirstTime)
+	}
+
+	restoredClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := restoredClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Nil(t, restoredClient.LastLoginInfo())
+}
+
+func TestAuthCacheKeysAreDistinctPerAccount(t *testing.T) {
+
+	newKeys := func(baseURL, userName string) authCacheKey {
+		account := newTestAccount()
+		account.UserName = userName
+		apiClient, err := NewClient(&account, baseURL, NewMockStore(), nil)
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		return apiClient.makeAuthCacheKeys()
+	}
+
+	sampleKeys := newKeys("https://readycash.example", "sample")
+	assert.Equal(t, sampleKeys, newKeys("https://readycash.example", "sample"))
+	assert.NotEqual(t, sampleKeys, newKeys("https://readycash.example", "another"))
+	assert.NotEqual(t, sampleKeys, newKeys("https://staging.readycash.example", "sample"))
+
+	assert.Regexp(t, `^[0-9a-f]{32}-auth-session$`, sampleKeys.sessionKey)
+}
+
+func TestChangePin(t *testing.T) {
+
+	testResults := struct {
+		payload map[string]string
+		status  int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == changePinUrl {
+			json.NewDecoder(req.Body).Decode(&testResults.payload)
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(testResults.status)
+			rw.Write([]byte(`{"Status": 400, "Code": 55, "Message": "incorrect pin"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	store := NewMockStore()
+	apiClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	assert.ErrorIs(t, apiClient.ChangePin("1234", ""), ErrMissingRequiredField)
+
+	testResults.status = http.StatusBadRequest
+	err = apiClient.ChangePin("9999", "5678")
+	assert.ErrorIs(t, err, ErrInvalidPin)
+	assert.Equal(t, "1234", apiClient.currentPin())
+
+	oldSession, _ := apiClient.cachedSession(context.Background())
+	expectedOldPin, _ := DesEncrypt([]byte("1234"), []byte("1234"))
+	assert.Equal(t, expectedOldPin, oldSession.encodedPin)
+
+	testResults.status = http.StatusOK
+	if err := apiClient.ChangePin("1234", "5678"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	expectedNewPin, _ := DesEncrypt([]byte("5678"), []byte("1234"))
+	assert.Equal(t, map[string]string{"oldPin": expectedOldPin, "newPin": expectedNewPin}, testResults.payload)
+
+	newSession, _ := apiClient.cachedSession(context.Background())
+	assert.Equal(t, expectedNewPin, newSession.encodedPin)
+	assert.Equal(t, expectedNewPin, apiClient.session().encodedPin)
+	assert.Equal(t, "5678", apiClient.currentPin())
+	assert.Equal(t, "1234", testAccount.Pin)
+}
+
+func TestChangePinKeepsNewerSession(t *testing.T) {
+
+	var apiClient *Client
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == changePinUrl {
+			apiClient.SetToken("Bearer Other", "other-session", "other-pin", time.Now().Add(time.Hour))
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	var err error
+	apiClient, err = NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	if err := apiClient.ChangePin("1234", "5678"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "5678", apiClient.currentPin())
+	assert.Equal(t, "other-session", apiClient.session().sessionID)
+	assert.Equal(t, "other-pin", apiClient.session().encodedPin)
+}
+
+func TestFetchTransactionDefaultWindow(t *testing.T) {
+
+	testResults := struct {
+		query url.Values
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.Path == baseTransactionsUrl {
+			testResults.query = req.URL.Query()
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`[]`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	before := time.Now()
+	if _, err := apiClient.FetchTransaction(&FetchTransactionOption{TranType: stringAddr("200.21.0001")}); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	startDate, _ := strconv.ParseInt(testResults.query.Get("start_date"), 10, 64)
+	endDate, _ := strconv.ParseInt(testResults.query.Get("end_date"), 10, 64)
+	assert.Equal(t, int64(thirtyDays/time.Millisecond), endDate-startDate)
+	assert.InDelta(t, before.UnixNano()/int64(time.Millisecond), endDate, float64(time.Minute/time.Millisecond))
+	assert.Equal(t, "200.21.0001", testResults.query.Get("trantype"))
+
+	if _, err := apiClient.FetchTransaction(&FetchTransactionOption{StartDate: intAddr(1622290322000)}); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "1622290322000", testResults.query.Get("start_date"))
+	assert.Empty(t, testResults.query.Get("end_date"))
+}
+
+func TestZeroSessionLengthUsesDefault(t *testing.T) {
+
+	testResults := struct {
+		loginCounter  int
+		sessionLength string
+	}{}
+
+	testAccount := newTestAccount()
+	testAccount.SessionLength = 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			req.ParseForm()
+			testResults.loginCounter += 1
+			testResults.sessionLength = req.Form.Get("sessionLength")
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := apiClient.BalanceEnquiry(); err != nil {
+			t.Fatalf("Did not expect call to fail: %v", err)
+		}
+	}
+
+	assert.Equal(t, 1, testResults.loginCounter)
+	assert.Equal(t, "1800", testResults.sessionLength)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), apiClient.SessionExpiry(), time.Minute)
+}
+
+func TestBankFundsTransferWithConfirmation(t *testing.T) {
+
+	transferRequest := BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Reference:     "user-defined-ref",
+	}
+
+	testResults := struct {
+		nameEnquiryCounter int
+		transferCounter    int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseNameEnquiry {
+			testResults.nameEnquiryCounter += 1
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"accountName": "JOHN  ADEBAYO DOE", "accountNumber": "0123456789", "bankCode": "058"}`))
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl {
+			testResults.transferCounter += 1
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070108", "status": "SUCCESSFUL"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.BankFundsTransferWithConfirmation(transferRequest, " john adebayo  Doe ")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "0000000000001070108", resp.TransactionRef)
+	assert.Equal(t, 1, testResults.nameEnquiryCounter)
+	assert.Equal(t, 1, testResults.transferCounter)
+
+	_, err = apiClient.BankFundsTransferWithConfirmation(transferRequest, "JANE DOE")
+	assert.ErrorIs(t, err, ErrNameMismatch)
+	assert.Equal(t, 2, testResults.nameEnquiryCounter)
+	assert.Equal(t, 1, testResults.transferCounter)
+
+	_, err = apiClient.BankFundsTransferWithConfirmation(transferRequest, "")
+	assert.ErrorIs(t, err, ErrNameMismatch)
+	assert.Equal(t, 1, testResults.transferCounter)
+}
+
+//failingWriteStore refuses writes while failWrites is set
+type failingWriteStore struct {
+	*mockStore
+	failWrites bool
+}
+
+func (s *failingWriteStore) SetStringFor(key, val string, exp time.Duration) error {
+	if s.failWrites {
+		return fmt.Errorf("write to %s failed", key)
+	}
+	return s.mockStore.SetStringFor(key, val, exp)
+}
+
+func TestCachedSessionIsWrittenAndReadAsAWhole(t *testing.T) {
+
+	var loginCounter int
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			loginCounter += 1
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	store := &failingWriteStore{mockStore: NewMockStore(), failWrites: true}
+	newClient := func() *Client {
+		apiClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		return apiClient
+	}
+
+	_, err := newClient().BalanceEnquiry()
+	assert.Error(t, err)
+	assert.Equal(t, 1, loginCounter)
+	assert.Empty(t, store.data, "a failed write should leave nothing behind")
+
+	store.failWrites = false
+	apiClient := newClient()
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, loginCounter)
+	assert.Len(t, store.data, 1)
+
+	cached, ok := apiClient.cachedSession(context.Background())
+	assert.True(t, ok)
+	current := apiClient.session()
+	assert.Equal(t, current.authorization, cached.authorization)
+	assert.Equal(t, current.sessionID, cached.sessionID)
+	assert.Equal(t, current.encodedPin, cached.encodedPin)
+	assert.Equal(t, current.expiration.Unix(), cached.expiration.Unix())
+
+	if _, err := newClient().BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, loginCounter, "the stored session should be restored")
+
+	partial, _ := authParams{
+		authorization: "Bearer Token",
+		sessionID:     "1234",
+		expiration:    time.Now().Add(time.Hour),
+	}.marshal()
+	assert.NoError(t, store.SetStringFor(apiClient.makeAuthCacheKeys().sessionKey, partial, time.Hour))
+
+	if _, err := newClient().BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 3, loginCounter, "a stored session without a pin should not be used")
+}
+
+func TestFetchTransactionDebitFilter(t *testing.T) {
+
+	pages := map[string]string{
+		"":   `[{"tranId": 30, "debit": true, "amount": 100}, {"tranId": 29, "debit": false, "amount": 200}]`,
+		"29": `[{"tranId": 28, "debit": false, "amount": 300}]`,
+		"28": `[{"tranId": 27, "debit": true, "amount": 400}]`,
+		"27": `[]`,
+	}
+
+	var debitParams []string
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.Path == baseTransactionsUrl {
+			debitParams = append(debitParams, req.URL.Query().Get("debit"))
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(pages[req.URL.Query().Get("after")]))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	tranIDs := func(transactions []WalletTransaction) []int64 {
+		ids := []int64{}
+		for _, transaction := range transactions {
+			ids = append(ids, transaction.TranID)
+		}
+		return ids
+	}
+
+	debits, err := apiClient.FetchTransaction(&FetchTransactionOption{Debit: boolAddr(true)})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, []int64{30}, tranIDs(debits))
+
+	credits, err := apiClient.FetchTransaction(&FetchTransactionOption{Debit: boolAddr(false)})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, []int64{29}, tranIDs(credits))
+
+	both, err := apiClient.FetchTransaction(&FetchTransactionOption{})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, []int64{30, 29}, tranIDs(both))
+	assert.Equal(t, []string{"true", "false", ""}, debitParams)
+
+	allDebits, err := apiClient.FetchAllTransactions(&FetchTransactionOption{Debit: boolAddr(true)}, 0)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, []int64{30, 27}, tranIDs(allDebits), "a page holding only credits should not end the walk")
+
+	assert.Equal(t, map[string]string{"debit": "false"}, FetchTransactionOption{Debit: boolAddr(false)}.ToMap())
+	assert.NotContains(t, FetchTransactionOption{}.ToMap(), "debit")
+}
+
+func TestBaseURLTrailingSlash(t *testing.T) {
+
+	var paths []string
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.Path)
+
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	var urls []string
+	for _, baseURL := range []string{server.URL, server.URL + "/", server.URL + "//", " " + server.URL + "/ "} {
+		apiClient, err := NewClient(&testAccount, baseURL, NewMockStore(), server.Client())
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		urls = append(urls, apiClient.generateUrl(baseTransactionsUrl, map[string]string{"after": "29"}))
+
+		paths = nil
+		if _, err := apiClient.BalanceEnquiry(); err != nil {
+			t.Fatalf("Did not expect call to fail for %q: %v", baseURL, err)
+		}
+		assert.Equal(t, []string{baseLoginUrl, baseBalanceUrl}, paths)
+	}
+
+	for _, generated := range urls {
+		assert.Equal(t, server.URL+baseTransactionsUrl+"?after=29", generated)
+	}
+}
+
+func TestEmptySuccessResponses(t *testing.T) {
+
+	var status int
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.Path == baseTransactionsUrl || req.URL.Path == changePinUrl ||
+			strings.HasPrefix(req.URL.Path, virtualBankAccountTransactionsUrl) {
+			rw.WriteHeader(status)
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for _, status = range []int{http.StatusOK, http.StatusNoContent} {
+		transactions, err := apiClient.FetchTransaction(nil)
+		if err != nil {
+			t.Fatalf("Did not expect call to fail with %d: %v", status, err)
+		}
+		assert.NotNil(t, transactions)
+		assert.Empty(t, transactions)
+
+		transactions, err = apiClient.FetchVirtualAccountTransactions("VA-1")
+		if err != nil {
+			t.Fatalf("Did not expect call to fail with %d: %v", status, err)
+		}
+		assert.Empty(t, transactions)
+
+		all, err := apiClient.FetchAllTransactions(nil, 0)
+		if err != nil {
+			t.Fatalf("Did not expect call to fail with %d: %v", status, err)
+		}
+		assert.Empty(t, all)
+	}
+
+	status = http.StatusNoContent
+	if err := apiClient.ChangePin("1234", "5678"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "5678", apiClient.currentPin())
+
+	status = http.StatusInternalServerError
+	_, err = apiClient.FetchTransaction(nil)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrEmptyResponse)
+}
+
+func TestFetchTransactionOptionSetTimes(t *testing.T) {
+
+	lagos := time.FixedZone("WAT", 60*60)
+
+	var options FetchTransactionOption
+	options.SetStartTime(time.Date(2021, 5, 29, 12, 12, 2, 0, time.UTC))
+	options.SetEndTime(time.Date(2021, 5, 29, 13, 12, 2, int(250*time.Millisecond), lagos))
+
+	assert.Equal(t, int64(1622290322000), *options.StartDate)
+	assert.Equal(t, int64(1622290322250), *options.EndDate)
+	assert.Equal(t, "1622290322000", options.ToMap()["start_date"])
+	assert.Equal(t, "1622290322250", options.ToMap()["end_date"])
+}
+
+func TestLoginWithoutSessionHeaders(t *testing.T) {
+
+	testResults := struct {
+		loginCalls   int
+		balanceCalls int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCalls++
+			rw.Header().Add("content-type", "application/json")
+			if testResults.loginCalls > 1 {
+				rw.Header().Add("Authorization", "Bearer Token")
+			}
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"first_time": false}`))
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			testResults.balanceCalls++
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	store := NewMockStore()
+	apiClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrLoginMissingCredentials)
+	assert.Contains(t, err.Error(), "Authorization, X-SessionID")
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrLoginMissingCredentials)
+	assert.Contains(t, err.Error(), "X-SessionID")
+	assert.NotContains(t, err.Error(), "Authorization")
+
+	assert.Equal(t, 2, testResults.loginCalls)
+	assert.Equal(t, 0, testResults.balanceCalls)
+	assert.False(t, apiClient.IsAuthenticated())
+	_, ok := apiClient.cachedSession(context.Background())
+	assert.False(t, ok)
+}
+
+func TestAuthenticate(t *testing.T) {
+
+	testResults := struct {
+		loginCounter int
+		failLogin    bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCounter += 1
+			if testResults.failLogin {
+				rw.WriteHeader(http.StatusUnauthorized)
+				rw.Write([]byte(`invalid credentials`))
+				return
+			}
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "5000","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	store := NewMockStore()
+	apiClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	if err := apiClient.Authenticate(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 1, testResults.loginCounter)
+	assert.True(t, apiClient.IsAuthenticated())
+
+	cached, ok := apiClient.cachedSession(context.Background())
+	assert.True(t, ok)
+	assert.Equal(t, "Bearer Token", cached.authorization)
+	assert.Equal(t, "1234", cached.sessionID)
+
+	assert.NoError(t, apiClient.Authenticate())
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 1, testResults.loginCounter)
+
+	restoredClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.NoError(t, restoredClient.Authenticate())
+	assert.Equal(t, 1, testResults.loginCounter)
+
+	testResults.failLogin = true
+	failingClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.ErrorIs(t, failingClient.Authenticate(), ErrLoginFailed)
+	assert.False(t, failingClient.IsAuthenticated())
+}
+
+func TestServerGrantedSessionLength(t *testing.T) {
+
+	testResults := struct {
+		header       string
+		body         string
+		loginCounter int
+	}{}
+
+	testAccount := newTestAccount()
+	testAccount.SessionLength = time.Hour
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCounter++
+			req.ParseForm()
+			assert.Equal(t, "3600", req.Form.Get("sessionLength"))
+
+			rw.Header().Add("content-type", "application/json")
+			rw.Header().Add("Authorization", "Bearer Token")
+			rw.Header().Add("X-SessionID", "1234")
+			if testResults.header != "" {
+				rw.Header().Add(sessionLengthHeader, testResults.header)
+			}
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(testResults.body))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name    string
+		header  string
+		body    string
+		granted time.Duration
+	}{
+		{"header", "600", `{}`, 10 * time.Minute},
+		{"body", "", `{"first_time": false, "session_length": 900}`, 15 * time.Minute},
+		{"header over body", "300", `{"session_length": 900}`, 5 * time.Minute},
+		{"not reported", "", `{}`, time.Hour},
+		{"unreadable", "soon", `not json`, time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testResults.header, testResults.body = tc.header, tc.body
+
+			clock := newFakeClock()
+			store := NewMockStore()
+			apiClient, err := NewClient(&testAccount, server.URL, store, server.Client(), WithClock(clock))
+			if err != nil {
+				t.Fatalf("Did not expect client creation to fail: %v", err)
+			}
+
+			if err := apiClient.Authenticate(); err != nil {
+				t.Fatalf("Did not expect call to fail: %v", err)
+			}
+			assert.Equal(t, clock.Now().Add(tc.granted), apiClient.SessionExpiry())
+
+			clock.Advance(tc.granted - time.Second)
+			assert.True(t, apiClient.IsAuthenticated())
+			clock.Advance(2 * time.Second)
+			assert.False(t, apiClient.IsAuthenticated())
+
+			loginsBefore := testResults.loginCounter
+			if err := apiClient.Authenticate(); err != nil {
+				t.Fatalf("Did not expect call to fail: %v", err)
+			}
+			assert.Equal(t, loginsBefore+1, testResults.loginCounter)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package readycash
+
+import "time"
+
+//Clock tells the client the current time. The client reads it for session
+//expiry, default transaction windows, generated references and the circuit
+//breaker, so tests can move time forward without sleeping
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
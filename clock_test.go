@@ -0,0 +1,164 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2021, 5, 29, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestWithClockExpiresSessionWithoutSleeping(t *testing.T) {
+
+	var loginCounter int
+
+	testAccount := newTestAccount()
+	testAccount.SessionLength = time.Hour
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			loginCounter += 1
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, clock.Now().Add(time.Hour).Unix(), apiClient.SessionExpiry().Unix())
+
+	clock.Advance(59 * time.Minute)
+	assert.True(t, apiClient.IsAuthenticated())
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 1, loginCounter)
+
+	clock.Advance(2 * time.Minute)
+	assert.False(t, apiClient.IsAuthenticated())
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, loginCounter)
+}
+
+func TestWithClockDrivesTimeDefaults(t *testing.T) {
+
+	testAccount := newTestAccount()
+	clock := newFakeClock()
+	apiClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil, WithClock(clock))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	first := apiClient.GenerateReference("")
+	clock.Advance(time.Millisecond)
+	second := apiClient.GenerateReference("")
+	assert.Less(t, first[:8], second[:8])
+	assert.Equal(t, padBase36(uint64(clock.Now().UnixNano()/int64(time.Millisecond)), 8), second[:8])
+
+	breaker := newCircuitBreaker(1, time.Minute)
+	assert.NoError(t, breaker.allow(clock.Now()))
+	breaker.record(context.Background(), clock.Now(), http.StatusBadGateway, nil)
+	assert.ErrorIs(t, breaker.allow(clock.Now()), ErrCircuitOpen)
+	clock.Advance(time.Minute)
+	assert.NoError(t, breaker.allow(clock.Now()))
+}
+
+func TestSessionExpiresWithin(t *testing.T) {
+
+	var loginCounter int
+
+	testAccount := newTestAccount()
+	testAccount.SessionLength = time.Hour
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			loginCounter += 1
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	assert.True(t, apiClient.SessionExpiresWithin(0), "there is no session before the first login")
+
+	if err := apiClient.Authenticate(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	clock.Advance(50 * time.Minute)
+	assert.False(t, apiClient.SessionExpiresWithin(10*time.Minute-time.Nanosecond))
+	assert.False(t, apiClient.SessionExpiresWithin(10*time.Minute))
+	assert.True(t, apiClient.SessionExpiresWithin(10*time.Minute+time.Nanosecond))
+	assert.True(t, apiClient.IsAuthenticated())
+
+	assert.NoError(t, apiClient.Logout())
+	if err := apiClient.Authenticate(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, loginCounter)
+	assert.False(t, apiClient.SessionExpiresWithin(10*time.Minute+time.Nanosecond))
+}
+
+func TestWithNilClock(t *testing.T) {
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil, WithClock(nil))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.Equal(t, realClock{}, apiClient.clock)
+	assert.True(t, apiClient.SessionExpiresWithin(time.Minute))
+}
@@ -0,0 +1,64 @@
+package readycash
+
+import "time"
+
+//DryRunRefPrefix starts the transaction ref of every response made up by
+//the client in dry run mode
+const DryRunRefPrefix = "DRYRUN-"
+
+//logDryRun records the payload a money moving call would have sent, the
+//encoded pin is left out
+func (r *Client) logDryRun(reqLogger Logger, payload map[string]interface{}) {
+	fields := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		if k != "pin" {
+			fields[k] = v
+		}
+	}
+	reqLogger.WithField("payload", fields).Info("dry run, request was not sent")
+}
+
+func dryRunTransferResponse(reference string, amount float64) *TransferResponse {
+	return &TransferResponse{
+		TransactionRef: DryRunRefPrefix + reference,
+		Status:         string(StatusSuccessful),
+		Amount:         amount,
+	}
+}
+
+func dryRunAirtimeResponse(reference string, amount float64) *AirtimeResponse {
+	return &AirtimeResponse{
+		TransactionRef: DryRunRefPrefix + reference,
+		Status:         string(StatusSuccessful),
+		Amount:         amount,
+	}
+}
+
+func dryRunUssdResponse(reference string, amount float64, clock Clock, loc *time.Location) *UssdTransactionResponse {
+	return &UssdTransactionResponse{
+		UserDefinedReference: reference,
+		TransactionRef:       DryRunRefPrefix + reference,
+		Amount:               amount,
+		ResponseCode:         ResponseCodeInProgress,
+		TransactionDate:      clock.Now().UnixNano() / int64(time.Millisecond),
+		Status:               string(StatusAwaitingCustomer),
+		clock:                clock,
+		location:             loc,
+	}
+}
+
+func dryRunBillPaymentResponse(reference string, amount float64) *BillPaymentResponse {
+	return &BillPaymentResponse{
+		TransactionRef: DryRunRefPrefix + reference,
+		Status:         string(StatusSuccessful),
+		Amount:         amount,
+	}
+}
+
+func dryRunReversalResponse(transactionRef string) *TransactionStatusResponse {
+	return &TransactionStatusResponse{
+		TransactionRef: DryRunRefPrefix + transactionRef,
+		TranType:       reversedTransactionType,
+		Status:         string(StatusSuccessful),
+	}
+}
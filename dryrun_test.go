@@ -0,0 +1,90 @@
+package readycash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunSkipsMoneyMovingRequests(t *testing.T) {
+
+	var requests []string
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	transfer, err := apiClient.BankFundsTransfer(BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Reference:     "bank-ref",
+	})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, DryRunRefPrefix+"bank-ref", transfer.TransactionRef)
+	assert.Equal(t, "SUCCESSFUL", transfer.Status)
+
+	walletTransfer, err := apiClient.WalletFundsTransfer("08031234567", 1500, "lunch", "wallet-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, DryRunRefPrefix+"wallet-ref", walletTransfer.TransactionRef)
+
+	airtime, err := apiClient.PurchaseAirtime("08031234567", 500, "MTN", "airtime-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, DryRunRefPrefix+"airtime-ref", airtime.TransactionRef)
+	assert.Equal(t, float64(500), airtime.Amount)
+
+	ussd, err := apiClient.GenerateUSSD("ussd-ref", 2000, "044")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "ussd-ref", ussd.UserDefinedReference)
+	assert.True(t, ussd.IsPending())
+
+	reversal, err := apiClient.ReverseTransaction("0000000000001070109", "customer request")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, DryRunRefPrefix+"0000000000001070109", reversal.TransactionRef)
+	assert.Equal(t, "SUCCESSFUL", reversal.Status)
+
+	assert.Empty(t, requests)
+
+	_, err = apiClient.BankFundsTransfer(BankTransferRequest{Amount: 0, Reference: "bank-ref"})
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, []string{baseLoginUrl, baseBalanceUrl}, requests)
+}
@@ -0,0 +1,170 @@
+package readycash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const redacted = "[REDACTED]"
+
+//redactedHeaders carry credentials and are never written to a traffic dump
+var redactedHeaders = map[string]struct{}{
+	"Authorization": {},
+	"X-Sessionid":   {},
+	"Cookie":        {},
+	"Set-Cookie":    {},
+}
+
+//redactedFields are the body fields, compared in lower case, holding a pin,
+//password or card number
+var redactedFields = map[string]struct{}{
+	"pin":         {},
+	"oldpin":      {},
+	"newpin":      {},
+	"password":    {},
+	"pan":         {},
+	"cardpan":     {},
+	"cardnumber":  {},
+	"card_number": {},
+}
+
+//WithTrafficDump writes every request the client sends, login included,
+//and the response it got to w. Credentials, pins, passwords and card
+//numbers are redacted but the dump still holds customer details, so keep
+//it only as long as the support case needs it
+func WithTrafficDump(w io.Writer) Option {
+	return func(c *Client) {
+		if w == nil {
+			c.dump = nil
+			return
+		}
+		c.dump = &trafficDump{w: w}
+	}
+}
+
+//trafficDump serialises the writes of concurrent calls to w
+type trafficDump struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+//write dumps req and the response to it, err is written instead of the
+//response when the request failed. A nil dump writes nothing
+func (d *trafficDump) write(req *http.Request, res *http.Response, data []byte, err error) {
+	if d == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, ">>> %s %s\n", req.Method, req.URL.String())
+	writeDumpHeaders(&buf, req.Header)
+	if req.GetBody != nil {
+		if body, bodyErr := req.GetBody(); bodyErr == nil {
+			contents, _ := ioutil.ReadAll(body)
+			body.Close()
+			buf.Write(redactBody(req.Header.Get("Content-Type"), contents))
+		}
+	}
+	buf.WriteString("\n")
+
+	if err != nil {
+		fmt.Fprintf(&buf, "<<< error: %v\n\n", err)
+	} else if res != nil {
+		fmt.Fprintf(&buf, "<<< %s\n", res.Status)
+		writeDumpHeaders(&buf, res.Header)
+		buf.Write(redactBody(res.Header.Get("Content-Type"), data))
+		buf.WriteString("\n\n")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.w.Write(buf.Bytes())
+}
+
+func writeDumpHeaders(buf *bytes.Buffer, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := strings.Join(header[key], ", ")
+		if _, ok := redactedHeaders[http.CanonicalHeaderKey(key)]; ok {
+			value = redacted
+		}
+		fmt.Fprintf(buf, "%s: %s\n", key, value)
+	}
+	buf.WriteString("\n")
+}
+
+//redactBody masks the redactedFields of a json or form body. Bodies without
+//any of them, or that are neither json nor a form, are returned as they are
+func redactBody(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		changed := false
+		for key := range values {
+			if _, ok := redactedFields[strings.ToLower(key)]; ok {
+				values[key] = []string{redacted}
+				changed = true
+			}
+		}
+		if !changed {
+			return body
+		}
+		return []byte(values.Encode())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	if !redactValue(value) {
+		return body
+	}
+	masked, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+//redactValue masks the redactedFields of the decoded json value in place
+//and reports whether there were any
+func redactValue(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := redactedFields[strings.ToLower(key)]; ok {
+				v[key] = redacted
+				changed = true
+				continue
+			}
+			changed = redactValue(field) || changed
+		}
+	case []interface{}:
+		for _, item := range v {
+			changed = redactValue(item) || changed
+		}
+	}
+	return changed
+}
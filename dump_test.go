@@ -0,0 +1,77 @@
+package readycash
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTrafficDump(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "RC-0001", "status": "SUCCESSFUL", "amount": 2500}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	var dump bytes.Buffer
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithTrafficDump(&dump))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BankFundsTransfer(BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Narration:     "school fees",
+		Reference:     "REF-0001",
+	})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	output := dump.String()
+	assert.Contains(t, output, ">>> POST "+server.URL+baseLoginUrl)
+	assert.Contains(t, output, ">>> POST "+server.URL+baseBankFundsTransferUrl)
+	assert.Contains(t, output, "<<< 200 OK")
+	assert.Contains(t, output, "Authorization: [REDACTED]")
+	assert.Contains(t, output, "X-Sessionid: [REDACTED]")
+	assert.Contains(t, output, `"pin":"[REDACTED]"`)
+	assert.Contains(t, output, "password=%5BREDACTED%5D")
+	assert.Contains(t, output, `"accountNumber":"0123456789"`)
+	assert.Contains(t, output, `"transactionRef": "RC-0001"`)
+
+	assert.NotContains(t, output, "Bearer Token")
+	assert.NotContains(t, output, "password="+testAccount.Password)
+	assert.NotContains(t, output, apiClient.session().encodedPin)
+}
+
+func TestRedactBody(t *testing.T) {
+
+	body := redactBody("application/json", []byte(`{"amount": 100.50, "card": {"cardNumber": "5399831234567890"}, "items": [{"PAN": "4111111111111111"}], "newPin": "abc"}`))
+	assert.JSONEq(t, `{"amount": 100.50, "card": {"cardNumber": "[REDACTED]"}, "items": [{"PAN": "[REDACTED]"}], "newPin": "[REDACTED]"}`, string(body))
+
+	body = redactBody("application/x-www-form-urlencoded", []byte(`userName=sample&password=secret`))
+	assert.Equal(t, "password=%5BREDACTED%5D&userName=sample", string(body))
+
+	assert.Equal(t, "not json", string(redactBody("text/plain", []byte("not json"))))
+	assert.Equal(t, `{"main": "1000"}`, string(redactBody("application/json", []byte(`{"main": "1000"}`))))
+}
@@ -0,0 +1,51 @@
+package readycash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+//ErrAPIUnreachable is returned by HealthCheck when the api could not be reached
+var ErrAPIUnreachable = errors.New("readycash api is unreachable")
+
+//HealthCheck logs in when needed and makes a balance enquiry to confirm the
+//base url and credentials work. Credential problems are reported as
+//ErrLoginFailed and network problems, timeouts, an open circuit breaker and
+//5xx responses as ErrAPIUnreachable. Logs, traces and observer events show
+//its request as BalanceEnquiry
+func (r *Client) HealthCheck() error {
+	return r.HealthCheckContext(context.Background())
+}
+
+//HealthCheckContext is like HealthCheck but uses ctx for the request
+func (r *Client) HealthCheckContext(ctx context.Context) error {
+	_, err := r.BalanceEnquiryContext(ctx)
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrForbiddenAfterRetry) {
+		return fmt.Errorf("%s %w", err.Error(), ErrLoginFailed)
+	}
+
+	if ctx.Err() == nil && isUnreachable(err) {
+		return fmt.Errorf("%s %w", err.Error(), ErrAPIUnreachable)
+	}
+
+	return err
+}
+
+//isUnreachable reports whether err means the api could not serve the
+//request: a network error, a request that ran past WithTimeout, an open
+//circuit breaker or a 5xx once retries ran out
+func isUnreachable(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var errResponse *ErrorResponse
+	return errors.As(err, &errResponse) && errResponse.HTTPStatus >= http.StatusInternalServerError
+}
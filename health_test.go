@@ -0,0 +1,128 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+
+	testResults := struct {
+		loginStatus   int
+		balanceStatus int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			if testResults.loginStatus != http.StatusOK {
+				rw.WriteHeader(testResults.loginStatus)
+				rw.Write([]byte(`invalid credentials`))
+				return
+			}
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(testResults.balanceStatus)
+			rw.Write([]byte(`{"balance": 1000}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	t.Run("reachable", func(t *testing.T) {
+		testResults.loginStatus, testResults.balanceStatus = http.StatusOK, http.StatusOK
+		apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		assert.NoError(t, apiClient.HealthCheck())
+	})
+
+	t.Run("bad credentials", func(t *testing.T) {
+		testResults.loginStatus, testResults.balanceStatus = http.StatusUnauthorized, http.StatusOK
+		apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		err = apiClient.HealthCheck()
+		assert.ErrorIs(t, err, ErrLoginFailed)
+		assert.NotErrorIs(t, err, ErrAPIUnreachable)
+	})
+
+	t.Run("forbidden after login", func(t *testing.T) {
+		testResults.loginStatus, testResults.balanceStatus = http.StatusOK, http.StatusForbidden
+		apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		assert.ErrorIs(t, apiClient.HealthCheck(), ErrLoginFailed)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachableURL := unreachable.URL
+		unreachable.Close()
+
+		apiClient, err := NewClient(&testAccount, unreachableURL, NewMockStore(), http.DefaultClient)
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		apiClient.SetRetryPolicy(RetryPolicy{})
+		err = apiClient.HealthCheck()
+		assert.ErrorIs(t, err, ErrAPIUnreachable)
+		assert.NotErrorIs(t, err, ErrLoginFailed)
+	})
+
+	t.Run("unreachable by timeout", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			writeTestLoginResponse(rw)
+		}))
+		defer slow.Close()
+
+		apiClient, err := NewClient(&testAccount, slow.URL, NewMockStore(), slow.Client(), WithTimeout(10*time.Millisecond))
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		apiClient.SetRetryPolicy(RetryPolicy{})
+		err = apiClient.HealthCheck()
+		assert.ErrorIs(t, err, ErrAPIUnreachable)
+		assert.NotErrorIs(t, err, ErrLoginFailed)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = apiClient.HealthCheckContext(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrAPIUnreachable)
+	})
+
+	t.Run("server errors and open circuit", func(t *testing.T) {
+		testResults.loginStatus, testResults.balanceStatus = http.StatusOK, http.StatusBadGateway
+		apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithCircuitBreaker(1, time.Minute))
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		apiClient.SetRetryPolicy(RetryPolicy{})
+
+		err = apiClient.HealthCheck()
+		assert.ErrorIs(t, err, ErrAPIUnreachable)
+		assert.NotContains(t, err.Error(), ErrCircuitOpen.Error())
+
+		err = apiClient.HealthCheck()
+		assert.ErrorIs(t, err, ErrAPIUnreachable)
+		assert.Contains(t, err.Error(), ErrCircuitOpen.Error())
+	})
+}
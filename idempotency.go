@@ -0,0 +1,35 @@
+package readycash
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+//IdempotencyKeyHeader is the header that carries the idempotency key of money
+//moving requests
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyKey struct{}
+
+//WithIdempotencyKey returns a copy of ctx that makes transfers, airtime
+//purchases, bill payments, reversals, ussd codes and virtual accounts use
+//key instead of a generated one, reuse the same key when resubmitting an
+//operation whose outcome is unknown
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+//ensureIdempotencyKey generates a key when ctx does not carry one yet, so
+//automatic retries and re-logins of the same call all send the same key
+func ensureIdempotencyKey(ctx context.Context) context.Context {
+	if idempotencyKeyFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithIdempotencyKey(ctx, uuid.NewString())
+}
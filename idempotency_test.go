@@ -0,0 +1,160 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeyIsStableAcrossRetries(t *testing.T) {
+
+	testResults := struct {
+		keys     []string
+		failures int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl || req.URL.String() == baseAirtimeUrl {
+			testResults.keys = append(testResults.keys, req.Header.Get(IdempotencyKeyHeader))
+			if len(testResults.keys) == 2 {
+				rw.WriteHeader(http.StatusForbidden)
+				rw.Write([]byte(`{"Status": 403, "Code": 403, "Message": "session expired"}`))
+				return
+			}
+			if len(testResults.keys) <= testResults.failures {
+				rw.WriteHeader(http.StatusInternalServerError)
+				rw.Write([]byte(`{"Status": 500, "Code": 500, "Message": "temporarily unavailable"}`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070108", "status": "SUCCESSFUL"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries:           3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             5 * time.Millisecond,
+		RetryIdempotentPosts: true,
+	})
+
+	testResults.failures = 3
+	_, err = apiClient.BankFundsTransfer(BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Reference:     "user-defined-ref",
+	})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Len(t, testResults.keys, 4)
+	assert.NotEmpty(t, testResults.keys[0])
+	for _, key := range testResults.keys {
+		assert.Equal(t, testResults.keys[0], key)
+	}
+	firstKey := testResults.keys[0]
+
+	testResults.keys, testResults.failures = nil, 0
+	ctx := WithIdempotencyKey(context.Background(), "caller-key")
+	_, err = apiClient.PurchaseAirtimeContext(ctx, "08031234567", 100, "MTN", "airtime-ref")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, []string{"caller-key"}, testResults.keys)
+
+	testResults.keys = nil
+	_, err = apiClient.BankFundsTransfer(BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Reference:     "another-ref",
+	})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.NotEqual(t, firstKey, testResults.keys[0])
+}
+
+func TestIdempotencyKeyOnRetriedCreatesAndReversals(t *testing.T) {
+
+	keys := map[string][]string{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.Path == baseUssdTransaction || req.URL.Path == virtualBankAccountUrl || req.URL.Path == reverseTransactionUrl {
+			keys[req.URL.Path] = append(keys[req.URL.Path], req.Header.Get(IdempotencyKeyHeader))
+			if len(keys[req.URL.Path]) == 1 {
+				rw.WriteHeader(http.StatusBadGateway)
+				rw.Write([]byte(`bad gateway`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070108", "status": "AWAITING CUSTOMER"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries:           1,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             5 * time.Millisecond,
+		RetryIdempotentPosts: true,
+	})
+
+	if _, err := apiClient.GenerateUSSD("ussd-ref", 1000, "044"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	if _, err := apiClient.CreateVirtualAccount(VirtualAccountRequest{CustomerName: "John Doe", Reference: "va-ref"}); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	if _, err := apiClient.ReverseTransaction("0000000000001070108", "customer request"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	for _, path := range []string{baseUssdTransaction, virtualBankAccountUrl, reverseTransactionUrl} {
+		if assert.Len(t, keys[path], 2, path) {
+			assert.NotEmpty(t, keys[path][0], path)
+			assert.Equal(t, keys[path][0], keys[path][1], path)
+		}
+	}
+	assert.NotEqual(t, keys[baseUssdTransaction][0], keys[virtualBankAccountUrl][0])
+}
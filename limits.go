@@ -0,0 +1,23 @@
+package readycash
+
+import "fmt"
+
+//AmountLimits bounds the amounts accepted by ussd generation, transfers and
+//airtime purchases before a request is sent, a zero value means no bound
+type AmountLimits struct {
+	MinAmount float64
+	MaxAmount float64
+}
+
+//checkAmount rejects amounts that are not positive or fall outside the
+//configured limits
+func (r *Client) checkAmount(amount float64) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	limits := r.amountLimits
+	if (limits.MinAmount > 0 && amount < limits.MinAmount) || (limits.MaxAmount > 0 && amount > limits.MaxAmount) {
+		return fmt.Errorf("%w: %.2f not within [%.2f, %.2f]", ErrAmountOutOfRange, amount, limits.MinAmount, limits.MaxAmount)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package readycash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmountLimits(t *testing.T) {
+
+	testResults := struct {
+		requestCounter int
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.requestCounter += 1
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.Header().Add("content-type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"transactionRef": "0000000000001070108", "status": "SUCCESSFUL"}`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithAmountLimits(AmountLimits{
+		MinAmount: 100,
+		MaxAmount: 50000,
+	}))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	transfer := func(amount float64) error {
+		_, err := apiClient.BankFundsTransfer(BankTransferRequest{
+			Amount:        amount,
+			AccountNumber: "0123456789",
+			BankCode:      "058",
+			Reference:     "user-defined-ref",
+		})
+		return err
+	}
+
+	t.Run("below min", func(t *testing.T) {
+		testResults.requestCounter = 0
+		_, err := apiClient.GenerateUSSD("user-defined-ref", 50, "044")
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+		assert.ErrorIs(t, transfer(99.99), ErrAmountOutOfRange)
+		_, err = apiClient.PurchaseAirtime("08031234567", 50, "MTN", "airtime-ref")
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+		assert.Equal(t, 0, testResults.requestCounter)
+	})
+
+	t.Run("above max", func(t *testing.T) {
+		testResults.requestCounter = 0
+		_, err := apiClient.GenerateUSSD("user-defined-ref", 50001, "044")
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+		assert.ErrorIs(t, transfer(1000000), ErrAmountOutOfRange)
+		_, err = apiClient.WalletFundsTransfer("08031234567", 60000, "lunch", "wallet-ref")
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+		assert.Equal(t, 0, testResults.requestCounter)
+	})
+
+	t.Run("not positive", func(t *testing.T) {
+		assert.ErrorIs(t, transfer(0), ErrInvalidAmount)
+		_, err := apiClient.GenerateUSSD("user-defined-ref", -5, "044")
+		assert.ErrorIs(t, err, ErrInvalidAmount)
+	})
+
+	t.Run("in range", func(t *testing.T) {
+		assert.NoError(t, transfer(100))
+		assert.NoError(t, transfer(50000))
+		_, err := apiClient.PurchaseAirtime("08031234567", 500, "MTN", "airtime-ref")
+		assert.NoError(t, err)
+		_, err = apiClient.GenerateUSSD("user-defined-ref", 1000, "044")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package readycash
+
+import "github.com/sirupsen/logrus"
+
+//Logger is the logging interface used by the client, fields attached with
+//WithField, WithFields and WithError are carried by the returned Logger
+type Logger interface {
+	WithField(key string, value interface{}) Logger
+	WithFields(fields map[string]interface{}) Logger
+	WithError(err error) Logger
+	Debug(msg string)
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+}
+
+//LevelSetter is implemented by loggers whose level can be changed through
+//Client.SetLogLevel
+type LevelSetter interface {
+	SetLevel(l LogLevel)
+}
+
+type logrusLogger struct {
+	logger *logrus.Logger
+	entry  *logrus.Entry
+}
+
+//NewLogrusLogger adapts a logrus logger to the Logger interface
+func NewLogrusLogger(l *logrus.Logger) Logger {
+	return &logrusLogger{logger: l, entry: logrus.NewEntry(l)}
+}
+
+func (l *logrusLogger) WithField(key string, value interface{}) Logger {
+	return &logrusLogger{logger: l.logger, entry: l.entry.WithField(key, value)}
+}
+
+func (l *logrusLogger) WithFields(fields map[string]interface{}) Logger {
+	return &logrusLogger{logger: l.logger, entry: l.entry.WithFields(fields)}
+}
+
+func (l *logrusLogger) WithError(err error) Logger {
+	return &logrusLogger{logger: l.logger, entry: l.entry.WithError(err)}
+}
+
+func (l *logrusLogger) Debug(msg string) {
+	l.entry.Debug(msg)
+}
+
+func (l *logrusLogger) Info(msg string) {
+	l.entry.Info(msg)
+}
+
+func (l *logrusLogger) Warn(msg string) {
+	l.entry.Warn(msg)
+}
+
+func (l *logrusLogger) Error(msg string) {
+	l.entry.Error(msg)
+}
+
+func (l *logrusLogger) SetLevel(level LogLevel) {
+	switch level {
+	case Debug:
+		l.logger.SetLevel(logrus.DebugLevel)
+	case Info:
+		l.logger.SetLevel(logrus.InfoLevel)
+	case Warn:
+		l.logger.SetLevel(logrus.WarnLevel)
+	case Error:
+		l.logger.SetLevel(logrus.ErrorLevel)
+	}
+}
@@ -0,0 +1,264 @@
+package readycash
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type capturedLog struct {
+	level   string
+	message string
+	fields  map[string]interface{}
+}
+
+type capturingLogger struct {
+	mu      *sync.Mutex
+	entries *[]capturedLog
+	fields  map[string]interface{}
+}
+
+func newCapturingLogger() *capturingLogger {
+	return &capturingLogger{
+		mu:      &sync.Mutex{},
+		entries: &[]capturedLog{},
+		fields:  map[string]interface{}{},
+	}
+}
+
+func (l *capturingLogger) WithField(key string, value interface{}) Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+func (l *capturingLogger) WithFields(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &capturingLogger{mu: l.mu, entries: l.entries, fields: merged}
+}
+
+func (l *capturingLogger) WithError(err error) Logger {
+	return l.WithField("error", err)
+}
+
+func (l *capturingLogger) Debug(msg string) { l.log("debug", msg) }
+func (l *capturingLogger) Info(msg string)  { l.log("info", msg) }
+func (l *capturingLogger) Warn(msg string)  { l.log("warn", msg) }
+func (l *capturingLogger) Error(msg string) { l.log("error", msg) }
+
+func (l *capturingLogger) log(level, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.entries = append(*l.entries, capturedLog{level: level, message: msg, fields: l.fields})
+}
+
+func (l *capturingLogger) captured() []capturedLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]capturedLog(nil), *l.entries...)
+}
+
+func TestClientUsesProvidedLogger(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`{"Status": 400, "Code": 400, "Message": "bad request"}`))
+	}))
+	defer server.Close()
+
+	logger := newCapturingLogger()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.FetchTransaction(nil)
+	assert.Error(t, err)
+
+	entries := logger.captured()
+	if assert.NotEmpty(t, entries) {
+		last := entries[len(entries)-1]
+		assert.Equal(t, "error", last.level)
+		assert.Equal(t, "FetchTransaction", last.fields["method"])
+		assert.Equal(t, http.StatusBadRequest, last.fields["status_code"])
+		assert.NotEmpty(t, last.fields["x-log-correlation-id"])
+	}
+}
+
+func TestLoggedPayloadsLeaveOutPins(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBankFundsTransferUrl || req.URL.String() == changePinUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070109", "status": "SUCCESSFUL"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	logger := newCapturingLogger()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BankFundsTransfer(BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Reference:     "bank-ref",
+	})
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	if err := apiClient.ChangePin("1234", "5678"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	oldPin, _ := DesEncrypt([]byte("1234"), []byte("1234"))
+	newPin, _ := DesEncrypt([]byte("5678"), []byte("1234"))
+	var payloads int
+	for _, entry := range logger.captured() {
+		logged := fmt.Sprintf("%v", entry.fields)
+		assert.NotContains(t, logged, oldPin)
+		assert.NotContains(t, logged, newPin)
+		if _, ok := entry.fields["payload"]; ok {
+			payloads++
+		}
+	}
+	assert.NotZero(t, payloads)
+}
+
+//stdLogger is an example adapter that writes client logs through the
+//standard library log package
+type stdLogger struct {
+	logger *log.Logger
+	fields map[string]interface{}
+}
+
+func (l stdLogger) WithField(key string, value interface{}) Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+func (l stdLogger) WithFields(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return stdLogger{logger: l.logger, fields: merged}
+}
+
+func (l stdLogger) WithError(err error) Logger {
+	return l.WithField("error", err)
+}
+
+func (l stdLogger) Debug(msg string) { l.print("DEBUG", msg) }
+func (l stdLogger) Info(msg string)  { l.print("INFO", msg) }
+func (l stdLogger) Warn(msg string)  { l.print("WARN", msg) }
+func (l stdLogger) Error(msg string) { l.print("ERROR", msg) }
+
+func (l stdLogger) print(level, msg string) {
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := []string{level, msg}
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, l.fields[k]))
+	}
+	l.logger.Println(strings.Join(parts, " "))
+}
+
+func ExampleWithLogger() {
+	logger := stdLogger{logger: log.New(os.Stdout, "", 0)}
+
+	account := &Account{UserName: "agent", Password: "secret", Pin: "1234"}
+	client, err := NewClient(account, "https://readycash.example.com", NewMockStore(), nil, WithLogger(logger))
+	if err != nil {
+		panic(err)
+	}
+
+	client.logger.WithField("method", "BalanceEnquiry").Info("using the standard library logger")
+	// Output: INFO using the standard library logger method=BalanceEnquiry
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRequestErrorsAreLoggedWithOperation(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		writeTestLoginResponse(rw)
+	}))
+	defer server.Close()
+
+	transport := server.Client().Transport
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == baseLoginUrl {
+			return transport.RoundTrip(req)
+		}
+		return nil, fmt.Errorf("connection reset by peer")
+	})}
+
+	logger := newCapturingLogger()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), httpClient, WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.Error(t, err)
+
+	var requestError *capturedLog
+	for _, entry := range logger.captured() {
+		if entry.message == "encountered error doing request" {
+			e := entry
+			requestError = &e
+		}
+	}
+	if assert.NotNil(t, requestError) {
+		assert.Equal(t, "BalanceEnquiry", requestError.fields["operation"])
+		assert.Equal(t, http.MethodGet, requestError.fields["method"])
+		assert.Contains(t, requestError.fields["url"], baseBalanceUrl)
+	}
+}
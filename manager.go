@@ -0,0 +1,147 @@
+package readycash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//defaultWarmConcurrency is how many accounts WarmAll logs in at a time
+const defaultWarmConcurrency = 4
+
+//Manager vends a Client per account, all sharing one Storage and
+//http.Client. Clients are cached by username so every caller working with
+//the same account shares its session instead of logging in again
+type Manager struct {
+	baseURL    string
+	storage    Storage
+	httpClient *http.Client
+	opts       []Option
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+//NewManager creates a Manager whose clients talk to baseUrl, opts are
+//applied to every client it creates
+func NewManager(baseUrl string, storage Storage, httpClient *http.Client, opts ...Option) *Manager {
+	return &Manager{
+		baseURL:    baseUrl,
+		storage:    storage,
+		httpClient: httpClient,
+		opts:       opts,
+		clients:    map[string]*Client{},
+	}
+}
+
+//Client returns the client for account, creating it on first use. Later
+//calls with the same username return the cached client even if the other
+//account fields differ
+func (m *Manager) Client(account *Account) (*Client, error) {
+	if account == nil {
+		return nil, ErrAccountCredentialsRequired
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if client, ok := m.clients[account.UserName]; ok {
+		return client, nil
+	}
+
+	client, err := NewClient(account, m.baseURL, m.storage, m.httpClient, m.opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.clients[account.UserName] = client
+	return client, nil
+}
+
+//WarmError is returned by WarmAll when some accounts could not log in, it
+//holds the login error of each of them by username
+type WarmError struct {
+	Errors map[string]error
+}
+
+func (e *WarmError) Error() string {
+	userNames := make([]string, 0, len(e.Errors))
+	for userName := range e.Errors {
+		userNames = append(userNames, userName)
+	}
+	sort.Strings(userNames)
+
+	failures := make([]string, len(userNames))
+	for i, userName := range userNames {
+		failures[i] = fmt.Sprintf("%s: %v", userName, e.Errors[userName])
+	}
+	return fmt.Sprintf("could not log in %d accounts: %s", len(failures), strings.Join(failures, "; "))
+}
+
+//Is reports whether the login error of any of the accounts matches target
+func (e *WarmError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+//WarmAll logs in every account the manager has vended a client for, a few
+//at a time, so their first calls do not wait on a login. Accounts that
+//already hold a session are left alone. The failures are returned together
+//as a *WarmError
+func (m *Manager) WarmAll(ctx context.Context) error {
+	m.mu.Lock()
+	clients := make([]*Client, 0, len(m.clients))
+	for _, client := range m.clients {
+		clients = append(clients, client)
+	}
+	m.mu.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		failedMu sync.Mutex
+		failed   = map[string]error{}
+	)
+	fail := func(client *Client, err error) {
+		failedMu.Lock()
+		defer failedMu.Unlock()
+		failed[client.account.UserName] = err
+	}
+	slots := make(chan struct{}, defaultWarmConcurrency)
+
+	for _, client := range clients {
+		if err := ctx.Err(); err != nil {
+			fail(client, err)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			fail(client, ctx.Err())
+			continue
+		case slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(client *Client) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := client.AuthenticateContext(ctx); err != nil {
+				fail(client, err)
+			}
+		}(client)
+	}
+
+	wg.Wait()
+	if len(failed) > 0 {
+		return &WarmError{Errors: failed}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerIsolatesAccounts(t *testing.T) {
+
+	testResults := struct {
+		logins map[string]int
+	}{logins: map[string]int{}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			req.ParseForm()
+			userName := req.Form.Get("userName")
+			testResults.logins[userName] += 1
+			rw.Header().Add("content-type", "application/json")
+			rw.Header().Add("Authorization", "Bearer "+userName)
+			rw.Header().Add("X-SessionID", "session-"+userName)
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{}`))
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	store := NewMockStore()
+	manager := NewManager(server.URL, store, server.Client())
+
+	firstAccount := newTestAccount()
+	secondAccount := newTestAccount()
+	secondAccount.UserName = "another"
+
+	firstClient, err := manager.Client(&firstAccount)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	secondClient, err := manager.Client(&secondAccount)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	assert.NotSame(t, firstClient, secondClient)
+	assert.NotEqual(t, firstClient.makeAuthCacheKeys(), secondClient.makeAuthCacheKeys())
+
+	sameAccount := newTestAccount()
+	cachedClient, err := manager.Client(&sameAccount)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.Same(t, firstClient, cachedClient)
+
+	for _, client := range []*Client{firstClient, secondClient, cachedClient} {
+		if _, err := client.BalanceEnquiry(); err != nil {
+			t.Fatalf("Did not expect call to fail: %v", err)
+		}
+	}
+
+	assert.Equal(t, map[string]int{"sample": 1, "another": 1}, testResults.logins)
+
+	firstSession, _ := firstClient.cachedSession(context.Background())
+	secondSession, _ := secondClient.cachedSession(context.Background())
+	assert.Equal(t, "Bearer sample", firstSession.authorization)
+	assert.Equal(t, "Bearer another", secondSession.authorization)
+
+	_, err = manager.Client(nil)
+	assert.ErrorIs(t, err, ErrAccountCredentialsRequired)
+}
+
+func TestManagerWarmAll(t *testing.T) {
+
+	var loginsMu sync.Mutex
+	logins := map[string]int{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			req.ParseForm()
+			userName := req.Form.Get("userName")
+			loginsMu.Lock()
+			logins[userName] += 1
+			loginsMu.Unlock()
+
+			if userName == "locked" {
+				rw.WriteHeader(http.StatusUnauthorized)
+				rw.Write([]byte(`account locked`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.Header().Add("Authorization", "Bearer "+userName)
+			rw.Header().Add("X-SessionID", "session-"+userName)
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	manager := NewManager(server.URL, NewMockStore(), server.Client())
+
+	var clients []*Client
+	for _, userName := range []string{"first", "second", "third"} {
+		account := newTestAccount()
+		account.UserName = userName
+		client, err := manager.Client(&account)
+		if err != nil {
+			t.Fatalf("Did not expect client creation to fail: %v", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := manager.WarmAll(context.Background()); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, map[string]int{"first": 1, "second": 1, "third": 1}, logins)
+	for _, client := range clients {
+		assert.True(t, client.IsAuthenticated())
+	}
+
+	assert.NoError(t, manager.WarmAll(context.Background()))
+	assert.Equal(t, map[string]int{"first": 1, "second": 1, "third": 1}, logins)
+
+	lockedAccount := newTestAccount()
+	lockedAccount.UserName = "locked"
+	if _, err := manager.Client(&lockedAccount); err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	err := manager.WarmAll(context.Background())
+	assert.ErrorIs(t, err, ErrLoginFailed)
+	var warmErr *WarmError
+	if assert.ErrorAs(t, err, &warmErr) {
+		assert.Len(t, warmErr.Errors, 1)
+		assert.Contains(t, warmErr.Errors, "locked")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = manager.WarmAll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	if assert.ErrorAs(t, err, &warmErr) {
+		assert.Len(t, warmErr.Errors, 4)
+	}
+}
@@ -0,0 +1,45 @@
+package readycash
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//Money is a naira amount sent by the api. It decodes json numbers written
+//as integers, decimals or in scientific notation, the same numbers quoted
+//as strings and null, which leaves it unchanged
+type Money float64
+
+//Float64 returns the amount as a float64
+func (m Money) Float64() float64 {
+	return float64(m)
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		var quoted string
+		if err := json.Unmarshal(data, &quoted); err != nil {
+			return err
+		}
+		raw = strings.TrimSpace(quoted)
+		if raw == "" {
+			*m = 0
+			return nil
+		}
+	}
+
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return fmt.Errorf("invalid money amount %s", string(data))
+	}
+	*m = Money(amount)
+	return nil
+}
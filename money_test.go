@@ -0,0 +1,64 @@
+package readycash
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+
+	cases := map[string]Money{
+		`4500`:      4500,
+		`4500.50`:   4500.50,
+		`4.5E+3`:    4500,
+		`4.5e3`:     4500,
+		`1.25E-1`:   0.125,
+		`-20`:       -20,
+		`"4500"`:    4500,
+		`"4500.50"`: 4500.50,
+		`"4.5E+3"`:  4500,
+		`" 4500 "`:  4500,
+		`""`:        0,
+	}
+
+	for input, expected := range cases {
+		var m Money
+		if err := json.Unmarshal([]byte(input), &m); err != nil {
+			t.Fatalf("Did not expect %s to fail: %v", input, err)
+		}
+		assert.Equal(t, expected, m, input)
+	}
+
+	m := Money(100)
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &m))
+	assert.Equal(t, Money(100), m)
+
+	for _, input := range []string{`"abc"`, `"NaN"`, `"Inf"`, `true`, `{}`} {
+		var m Money
+		assert.Error(t, json.Unmarshal([]byte(input), &m), input)
+	}
+}
+
+func TestRecieptAmountAcceptsAnyNotation(t *testing.T) {
+
+	transactions, err := NewWalletTransactions([]byte(`[
+		{"tranId": 1, "reciept": {"amount": 4.5E+3, "reference": "628935"}},
+		{"tranId": 2, "reciept": {"amount": "4500.00", "reference": "628936"}},
+		{"tranId": 3, "reciept": {"amount": 4500, "reference": "628937"}}
+	]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	for _, transaction := range transactions {
+		assert.Equal(t, float64(4500), transaction.Reciept.Amount.Float64())
+	}
+
+	data, err := json.Marshal(transactions[0].Reciept)
+	if err != nil {
+		t.Fatalf("Did not expect marshalling to fail: %v", err)
+	}
+	assert.Contains(t, string(data), `"amount":4500`)
+}
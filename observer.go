@@ -0,0 +1,15 @@
+package readycash
+
+import "time"
+
+//Observer receives a callback for every http request the client makes so
+//metrics can be collected without the package depending on a metrics
+//library. op is the client method that made the request, retried attempts
+//are reported separately
+type Observer interface {
+	ObserveRequest(op string, statusCode int, duration time.Duration, err error)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) ObserveRequest(string, int, time.Duration, error) {}
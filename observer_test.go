@@ -0,0 +1,74 @@
+package readycash
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observedRequest struct {
+	op         string
+	statusCode int
+	duration   time.Duration
+	err        error
+}
+
+type capturingObserver struct {
+	mu       sync.Mutex
+	requests []observedRequest
+}
+
+func (o *capturingObserver) ObserveRequest(op string, statusCode int, duration time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests = append(o.requests, observedRequest{op, statusCode, duration, err})
+}
+
+func TestObserverIsCalledPerRequest(t *testing.T) {
+
+	server, _ := newFlakyServer(baseBalanceUrl, 1, `{"income": "5000","main": "1000"}`)
+	defer server.Close()
+
+	observer := &capturingObserver{}
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithObserver(observer))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Millisecond,
+	})
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	_, err = apiClient.FetchTransaction(nil)
+	assert.Error(t, err)
+
+	if assert.Len(t, observer.requests, 3) {
+		assert.Equal(t, "BalanceEnquiry", observer.requests[0].op)
+		assert.Equal(t, http.StatusInternalServerError, observer.requests[0].statusCode)
+		assert.Equal(t, "BalanceEnquiry", observer.requests[1].op)
+		assert.Equal(t, http.StatusOK, observer.requests[1].statusCode)
+		assert.Equal(t, "FetchTransaction", observer.requests[2].op)
+		assert.Equal(t, http.StatusBadRequest, observer.requests[2].statusCode)
+		for _, request := range observer.requests {
+			assert.NoError(t, request.err)
+			assert.Greater(t, int64(request.duration), int64(0))
+		}
+	}
+
+	server.Close()
+	_, err = apiClient.FetchTransaction(nil)
+	assert.Error(t, err)
+	if assert.Len(t, observer.requests, 6) {
+		assert.Equal(t, "FetchTransaction", observer.requests[5].op)
+		assert.Equal(t, 0, observer.requests[5].statusCode)
+		assert.Error(t, observer.requests[5].err)
+	}
+}
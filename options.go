@@ -0,0 +1,191 @@
+package readycash
+
+import "time"
+
+//Option configures optional behaviour of a Client
+type Option func(*Client)
+
+//WithLogger replaces the default logrus backed logger
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		if l != nil {
+			c.logger = l
+		}
+	}
+}
+
+//WithTimeout bounds every request the client makes, logins included, to d.
+//It layers on top of any Timeout set on the injected http.Client, whichever
+//is shorter wins, and each retry attempt gets a fresh deadline
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+//WithObserver reports every request the client makes to o
+func WithObserver(o Observer) Option {
+	return func(c *Client) {
+		if o != nil {
+			c.observer = o
+		}
+	}
+}
+
+//WithTracer opens a span through t around every api operation
+func WithTracer(t Tracer) Option {
+	return func(c *Client) {
+		if t != nil {
+			c.tracer = t
+		}
+	}
+}
+
+//WithAmountLimits rejects money operations outside l with ErrAmountOutOfRange
+//before any request is made
+func WithAmountLimits(l AmountLimits) Option {
+	return func(c *Client) {
+		c.amountLimits = l
+	}
+}
+
+//WithUserAgent replaces the default readycash-go User-Agent sent on every
+//request, logins included
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		if userAgent != "" {
+			c.userAgent = userAgent
+		}
+	}
+}
+
+//WithDefaultHeaders adds headers to every request, logins included. Headers
+//the client manages itself, like Authorization and X-SessionID, are never
+//replaced
+func WithDefaultHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		c.defaultHeaders = make(map[string]string, len(headers))
+		for key, value := range headers {
+			c.defaultHeaders[key] = value
+		}
+	}
+}
+
+//WithPinEncryptor replaces the DES encryption used for the pin, only use it
+//when the api has been set up for the other scheme
+func WithPinEncryptor(e PinEncryptor) Option {
+	return func(c *Client) {
+		if e != nil {
+			c.pinEncryptor = e
+		}
+	}
+}
+
+//WithLocation sets the timezone used for the FormattedDate of transactions
+//and their receipts, the default is UTC
+func WithLocation(loc *time.Location) Option {
+	return func(c *Client) {
+		if loc != nil {
+			c.location = loc
+		}
+	}
+}
+
+//WithBatchConcurrency limits how many transfers BatchBankTransfer makes at
+//the same time, the default is 4
+func WithBatchConcurrency(n int) Option {
+	return func(c *Client) {
+		c.batchConcurrency = n
+	}
+}
+
+//WithRateLimiter makes the client wait on limiter before every request it
+//sends, including logins and retried attempts
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+//WithCircuitBreaker makes the client fail fast with ErrCircuitOpen after
+//threshold consecutive network errors or 5xx responses. Once cooldown has
+//passed a single call is let through and a success closes the circuit again
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		if threshold <= 0 {
+			c.breaker = nil
+			return
+		}
+		c.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+//WithOnLogin registers fn to be called after every successful login made
+//over the network, sessions restored from storage do not trigger it
+func WithOnLogin(fn func(LoginResult)) Option {
+	return func(c *Client) {
+		c.onLogin = fn
+	}
+}
+
+//WithDryRun stops GenerateUSSD, the fund transfers, airtime purchases, bill
+//payments and reversals from reaching the api. Their input is still
+//validated and logged and a made up response whose TransactionRef starts
+//with DryRunRefPrefix is returned. Read only calls are not affected
+func WithDryRun(dryRun bool) Option {
+	return func(c *Client) {
+		c.dryRun = dryRun
+	}
+}
+
+//WithBanksCacheTTL sets how long ListBanks and ResolveBankCode reuse the
+//institution list kept in storage, the default is 24 hours
+func WithBanksCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.banksCacheTTL = ttl
+	}
+}
+
+//WithClock makes the client read the current time from clock instead of
+//the system clock, a nil clock is ignored
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
+//WithResponseSizeLimit caps how many bytes of a response body are read, a
+//larger body fails the call with ErrResponseTooLarge. The default is 4MB
+func WithResponseSizeLimit(limit int64) Option {
+	return func(c *Client) {
+		c.maxResponseSize = limit
+	}
+}
+
+//WithAuditSink sends a record of every money moving call to sink, calls
+//made in dry run mode are not audited
+func WithAuditSink(sink AuditSink) Option {
+	return func(c *Client) {
+		c.auditSink = sink
+	}
+}
+
+//WithLoginBackoff stops the client from logging in again for backoff after
+//a login the api refused, calls made in the meantime fail with the error of
+//that login. Without it every call tries to log in
+func WithLoginBackoff(backoff time.Duration) Option {
+	return func(c *Client) {
+		c.loginBackoff = backoff
+	}
+}
+
+//WithDisableAutoLogin stops the client from ever calling the login endpoint,
+//sessions have to come from SetToken or storage. Once the session expires
+//calls fail with ErrSessionExpired
+func WithDisableAutoLogin() Option {
+	return func(c *Client) {
+		c.disableAutoLogin = true
+	}
+}
@@ -0,0 +1,434 @@
+package readycash
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeoutBoundsSlowRequests(t *testing.T) {
+
+	testResults := struct {
+		slowLogin bool
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl && !testResults.slowLogin {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		ioutil.ReadAll(req.Body)
+		select {
+		case <-req.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"income": "0","main": "1000"}`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), http.DefaultClient, WithTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	start := time.Now()
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	testResults.slowLogin = true
+	apiClient, err = NewClient(&testAccount, server.URL, NewMockStore(), http.DefaultClient, WithTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	start = time.Now()
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestWithUserAgent(t *testing.T) {
+
+	testResults := struct {
+		userAgents map[string]string
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.userAgents[req.URL.Path] = req.Header.Get("User-Agent")
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.Header().Add("content-type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"income": "0","main": "1000"}`))
+	}))
+	defer server.Close()
+
+	testResults.userAgents = map[string]string{}
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, map[string]string{
+		baseLoginUrl:   "readycash-go/" + Version,
+		baseBalanceUrl: "readycash-go/" + Version,
+	}, testResults.userAgents)
+
+	testResults.userAgents = map[string]string{}
+	apiClient, err = NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithUserAgent("agent-portal/2.3"))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, map[string]string{
+		baseLoginUrl:   "agent-portal/2.3",
+		baseBalanceUrl: "agent-portal/2.3",
+	}, testResults.userAgents)
+}
+
+func TestWithDefaultHeaders(t *testing.T) {
+
+	testResults := struct {
+		headers map[string]http.Header
+	}{headers: map[string]http.Header{}}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		testResults.headers[req.URL.Path] = req.Header.Clone()
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.Header().Add("content-type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"income": "0","main": "1000"}`))
+	}))
+	defer server.Close()
+
+	headers := map[string]string{
+		"X-Api-Key":     "gateway-key",
+		"authorization": "Bearer Override",
+		"X-SessionID":   "override",
+	}
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithDefaultHeaders(headers))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	headers["X-Api-Key"] = "changed-after"
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	loginHeaders := testResults.headers[baseLoginUrl]
+	assert.Equal(t, "gateway-key", loginHeaders.Get("X-Api-Key"))
+	assert.Empty(t, loginHeaders.Values("Authorization"))
+	assert.Equal(t, "application/x-www-form-urlencoded", loginHeaders.Get("Content-Type"))
+
+	balanceHeaders := testResults.headers[baseBalanceUrl]
+	assert.Equal(t, "gateway-key", balanceHeaders.Get("X-Api-Key"))
+	assert.Equal(t, []string{"Bearer Token"}, balanceHeaders.Values("Authorization"))
+	assert.Equal(t, []string{"1234"}, balanceHeaders.Values("X-SessionID"))
+}
+
+func TestWithLocation(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.Header().Add("content-type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`[{"tranId": 1, "date": 1622307120000, "reciept": {"date": 1622307120000}}]`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithLocation(time.FixedZone("WAT", 60*60)))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	transactions, err := apiClient.FetchTransaction(nil)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "2021-05-29T17:52:00+01:00", transactions[0].FormattedDate)
+	assert.Equal(t, "2021-05-29T17:52:00+01:00", transactions[0].Reciept.FormattedDate)
+}
+
+func TestWithOnLogin(t *testing.T) {
+
+	testResults := struct {
+		forbidNext bool
+		logins     []LoginResult
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.Header().Add("Authorization", "Bearer Token")
+			rw.Header().Add("X-SessionID", "1234")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"first_time": true}`))
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			if testResults.forbidNext {
+				testResults.forbidNext = false
+				rw.WriteHeader(http.StatusForbidden)
+				rw.Write([]byte(`{"Status": 403, "Code": 403, "Message": "session expired"}`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	onLogin := WithOnLogin(func(result LoginResult) {
+		testResults.logins = append(testResults.logins, result)
+	})
+
+	store := NewMockStore()
+	apiClient, err := NewClient(&testAccount, server.URL, store, server.Client(), onLogin)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := apiClient.BalanceEnquiry(); err != nil {
+			t.Fatalf("Did not expect call to fail: %v", err)
+		}
+	}
+	if assert.Len(t, testResults.logins, 1) {
+		assert.True(t, testResults.logins[0].FirstTime)
+	}
+
+	restoredClient, err := NewClient(&testAccount, server.URL, store, server.Client(), onLogin)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := restoredClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Len(t, testResults.logins, 1, "a session restored from storage is not a login")
+
+	testResults.forbidNext = true
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Len(t, testResults.logins, 2)
+}
+
+func TestWithResponseSizeLimit(t *testing.T) {
+
+	var balanceCalls int
+
+	testAccount := newTestAccount()
+	balanceBody := `{"income": "0","main": "1000"}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			balanceCalls++
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(balanceBody))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithResponseSizeLimit(int64(len(balanceBody))))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{MaxRetries: 2})
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	balanceBody = `{"income": "0","main": "1000", "padding": "` + strings.Repeat("x", 1<<20) + `"}`
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+	assert.Equal(t, 2, balanceCalls, "an oversized body should not be retried")
+
+	defaultClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	if _, err := defaultClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+}
+
+func TestWithLoginBackoff(t *testing.T) {
+
+	testResults := struct {
+		loginCounter int
+		rejectLogin  bool
+	}{rejectLogin: true}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCounter++
+			if testResults.rejectLogin {
+				rw.WriteHeader(http.StatusUnauthorized)
+				rw.Write([]byte(`invalid credentials`))
+				return
+			}
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithLoginBackoff(time.Minute), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := apiClient.BalanceEnquiry()
+		assert.ErrorIs(t, err, ErrLoginFailed)
+		assert.Contains(t, err.Error(), "invalid credentials")
+		clock.Advance(10 * time.Second)
+	}
+	assert.Equal(t, 1, testResults.loginCounter)
+
+	clock.Advance(time.Minute)
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrLoginFailed)
+	assert.Equal(t, 2, testResults.loginCounter)
+
+	testResults.rejectLogin = false
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrLoginFailed, "the cooldown should still hold after the server recovers")
+	assert.Equal(t, 2, testResults.loginCounter)
+
+	clock.Advance(time.Minute)
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 3, testResults.loginCounter)
+
+	withoutBackoff, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	testResults.rejectLogin = true
+	for i := 0; i < 2; i++ {
+		_, err := withoutBackoff.BalanceEnquiry()
+		assert.ErrorIs(t, err, ErrLoginFailed)
+	}
+	assert.Equal(t, 5, testResults.loginCounter)
+}
+
+func TestWithDisableAutoLogin(t *testing.T) {
+
+	testResults := struct {
+		loginCounter  int
+		authorization string
+		sessionID     string
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			testResults.loginCounter++
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			testResults.authorization = req.Header.Get("Authorization")
+			testResults.sessionID = req.Header.Get("X-SessionID")
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithDisableAutoLogin(), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrSessionExpired)
+
+	apiClient.SetToken("Bearer Injected", "injected-session", "encoded-pin", clock.Now().Add(time.Hour))
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "Bearer Injected", testResults.authorization)
+	assert.Equal(t, "injected-session", testResults.sessionID)
+	assert.Equal(t, "encoded-pin", apiClient.session().encodedPin)
+
+	clock.Advance(2 * time.Hour)
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, ErrSessionExpired)
+	assert.Equal(t, 0, testResults.loginCounter)
+}
@@ -0,0 +1,33 @@
+package readycash
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//nigerianSubscriberRegex matches the ten digits of a nigerian mobile number
+//that follow the leading 0 or the 234 country code
+var nigerianSubscriberRegex = regexp.MustCompile(`^[789][01]\d{8}$`)
+
+//phoneSeparators are dropped from a phone number before it is parsed
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+//NormalizePhoneNumber turns a nigerian mobile number written as
+//08031234567, +2348031234567, 2348031234567 or 002348031234567, with or
+//without spaces and dashes, into the 11 digit local form the api expects.
+//Anything else fails with ErrInvalidPhone
+func NormalizePhoneNumber(phone string) (string, error) {
+	subscriber := phoneSeparators.Replace(strings.TrimSpace(phone))
+	for _, prefix := range []string{"+234", "00234", "234", "0"} {
+		if strings.HasPrefix(subscriber, prefix) {
+			subscriber = strings.TrimPrefix(subscriber, prefix)
+			break
+		}
+	}
+
+	if !nigerianSubscriberRegex.MatchString(subscriber) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPhone, phone)
+	}
+	return "0" + subscriber, nil
+}
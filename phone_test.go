@@ -0,0 +1,75 @@
+package readycash
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+
+	for _, phone := range []string{
+		"08031234567",
+		"+2348031234567",
+		"2348031234567",
+		"002348031234567",
+		"8031234567",
+		" 0803 123 4567 ",
+		"+234 803-123-4567",
+		"(0803) 123 4567",
+	} {
+		normalized, err := NormalizePhoneNumber(phone)
+		if assert.NoError(t, err, "phone %q", phone) {
+			assert.Equal(t, "08031234567", normalized, "phone %q", phone)
+		}
+	}
+
+	normalized, err := NormalizePhoneNumber("+2349061234567")
+	assert.NoError(t, err)
+	assert.Equal(t, "09061234567", normalized)
+
+	for _, phone := range []string{"", "0803123", "12345678901", "+1 202 555 0100", "06031234567", "080312345678", "0803123456a"} {
+		_, err := NormalizePhoneNumber(phone)
+		assert.ErrorIs(t, err, ErrInvalidPhone, "phone %q", phone)
+		assert.ErrorIs(t, err, ErrInvalidRecipientPhone, "phone %q", phone)
+	}
+}
+
+func TestWalletFundsTransferNormalizesPhone(t *testing.T) {
+
+	var payload map[string]interface{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseWalletFundsTransferUrl {
+			json.NewDecoder(req.Body).Decode(&payload)
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070109", "status": "SUCCESSFUL"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	if _, err := apiClient.WalletFundsTransfer("2348031234567", 500, "lunch", "user-defined-ref"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "08031234567", payload["phone"])
+}
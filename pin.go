@@ -0,0 +1,74 @@
+package readycash
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+//PinEncryptor encrypts the account pin with the session id as key before it
+//is sent to the api, the result must be hex encoded
+type PinEncryptor interface {
+	Encrypt(pin, key []byte) (string, error)
+	Decrypt(encoded string, key []byte) ([]byte, error)
+}
+
+//DESPinEncryptor is the default PinEncryptor, it uses the triple DES scheme
+//of DesEncrypt
+type DESPinEncryptor struct{}
+
+func (DESPinEncryptor) Encrypt(pin, key []byte) (string, error) {
+	return DesEncrypt(pin, key)
+}
+
+func (DESPinEncryptor) Decrypt(encoded string, key []byte) ([]byte, error) {
+	return DesDecrypt(encoded, key)
+}
+
+//AESPinEncryptor encrypts the pin with AES-256-GCM using the sha256 of the
+//key, the random nonce is prepended to the sealed pin
+type AESPinEncryptor struct{}
+
+func (AESPinEncryptor) Encrypt(pin, key []byte) (string, error) {
+	gcm, err := newPinGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(gcm.Seal(nonce, nonce, pin, nil)), nil
+}
+
+func (AESPinEncryptor) Decrypt(encoded string, key []byte) ([]byte, error) {
+	sealed, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newPinGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted pin is too short")
+	}
+	nonce, crypted := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, crypted, nil)
+}
+
+func newPinGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) < 1 {
+		return nil, errors.New("wrong data or key")
+	}
+	aesKey := sha256.Sum256(key)
+	block, err := aes.NewCipher(aesKey[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
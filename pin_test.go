@@ -0,0 +1,87 @@
+package readycash
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinEncryptorsRoundTrip(t *testing.T) {
+
+	encryptors := map[string]PinEncryptor{
+		"des": DESPinEncryptor{},
+		"aes": AESPinEncryptor{},
+	}
+
+	for name, encryptor := range encryptors {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := encryptor.Encrypt([]byte("1234"), []byte("session-id"))
+			if err != nil {
+				t.Fatalf("Did not expect encryption to fail: %v", err)
+			}
+			_, err = hex.DecodeString(encoded)
+			assert.NoError(t, err)
+			assert.NotContains(t, encoded, "1234")
+
+			pin, err := encryptor.Decrypt(encoded, []byte("session-id"))
+			if err != nil {
+				t.Fatalf("Did not expect decryption to fail: %v", err)
+			}
+			assert.Equal(t, "1234", string(pin))
+		})
+	}
+
+	desEncoded, _ := DESPinEncryptor{}.Encrypt([]byte("1234"), []byte("session-id"))
+	expected, _ := DesEncrypt([]byte("1234"), []byte("session-id"))
+	assert.Equal(t, expected, desEncoded)
+
+	aesEncoded, _ := AESPinEncryptor{}.Encrypt([]byte("1234"), []byte("session-id"))
+	_, err := AESPinEncryptor{}.Decrypt(aesEncoded, []byte("other-session"))
+	assert.Error(t, err)
+}
+
+func TestWithPinEncryptor(t *testing.T) {
+
+	var encodedPin string
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseWalletFundsTransferUrl {
+			var payload map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&payload)
+			encodedPin, _ = payload["pin"].(string)
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070108", "status": "SUCCESSFUL"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithPinEncryptor(AESPinEncryptor{}))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	if _, err := apiClient.WalletFundsTransfer("08031234567", 1500, "lunch", "wallet-ref"); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	pin, err := AESPinEncryptor{}.Decrypt(encodedPin, []byte("1234"))
+	if err != nil {
+		t.Fatalf("Did not expect decryption to fail: %v", err)
+	}
+	assert.Equal(t, testAccount.Pin, string(pin))
+}
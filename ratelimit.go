@@ -0,0 +1,19 @@
+package readycash
+
+import "context"
+
+//RateLimiter paces the requests made to the api. *rate.Limiter from
+//golang.org/x/time/rate satisfies it, so the package does not need to
+//depend on it
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+//waitForRateLimit blocks until the limiter allows another request or ctx
+//is done, it returns straight away when no limiter is configured
+func (r *Client) waitForRateLimit(ctx context.Context) error {
+	if r.rateLimiter == nil {
+		return nil
+	}
+	return r.rateLimiter.Wait(ctx)
+}
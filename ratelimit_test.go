@@ -0,0 +1,114 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//intervalLimiter lets one request through every interval
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *intervalLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+
+	var mu sync.Mutex
+	var requestTimes []time.Time
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requestTimes = append(requestTimes, time.Now())
+		mu.Unlock()
+
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "0","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	interval := 50 * time.Millisecond
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithRateLimiter(&intervalLimiter{interval: interval}))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := apiClient.BalanceEnquiry(); err != nil {
+				t.Errorf("Did not expect call to fail: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, requestTimes, 4)
+	for i := 1; i < len(requestTimes); i++ {
+		assert.GreaterOrEqual(t, int64(requestTimes[i].Sub(requestTimes[i-1])), int64(interval-5*time.Millisecond))
+	}
+}
+
+func TestRateLimiterRespectsContext(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Errorf("Did not expect a request to %s", req.URL.String())
+	}))
+	defer server.Close()
+
+	limiter := &intervalLimiter{interval: time.Hour, next: time.Now().Add(time.Hour)}
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client(), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = apiClient.BalanceEnquiryContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,87 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+)
+
+type rawResponseKey struct{}
+
+//RawResponse holds the http status, headers and body exactly as the api
+//returned them, when a request is retried it holds the final attempt
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+//WithRawResponse returns a copy of ctx that records the response of any
+//request made with it into raw, it works with all the Context methods
+func WithRawResponse(ctx context.Context, raw *RawResponse) context.Context {
+	return context.WithValue(ctx, rawResponseKey{}, raw)
+}
+
+func rawResponseFromContext(ctx context.Context) *RawResponse {
+	raw, _ := ctx.Value(rawResponseKey{}).(*RawResponse)
+	return raw
+}
+
+//recordRawResponse copies the response into the RawResponse attached to ctx
+func recordRawResponse(ctx context.Context, res *http.Response, data []byte) {
+	raw := rawResponseFromContext(ctx)
+	if raw == nil {
+		return
+	}
+	raw.StatusCode = res.StatusCode
+	raw.Header = res.Header.Clone()
+	raw.Body = append([]byte(nil), data...)
+}
+
+//BalanceEnquiryRaw is like BalanceEnquiry but also returns the raw response
+func (r *Client) BalanceEnquiryRaw() (*BalanceEnquiryResponse, *RawResponse, error) {
+	var raw RawResponse
+	resp, err := r.BalanceEnquiryContext(WithRawResponse(context.Background(), &raw))
+	return resp, &raw, err
+}
+
+//GenerateUSSDRaw is like GenerateUSSD but also returns the raw response
+func (r *Client) GenerateUSSDRaw(reference string, amount float64, bankCode string) (*UssdTransactionResponse, *RawResponse, error) {
+	var raw RawResponse
+	resp, err := r.GenerateUSSDContext(WithRawResponse(context.Background(), &raw), reference, amount, bankCode)
+	return resp, &raw, err
+}
+
+//FetchUSSDTransactionRaw is like FetchUSSDTransaction but also returns the raw response
+func (r *Client) FetchUSSDTransactionRaw(reference string) (*UssdTransactionResponse, *RawResponse, error) {
+	var raw RawResponse
+	resp, err := r.FetchUSSDTransactionContext(WithRawResponse(context.Background(), &raw), reference)
+	return resp, &raw, err
+}
+
+//BankFundsTransferRaw is like BankFundsTransfer but also returns the raw response
+func (r *Client) BankFundsTransferRaw(req BankTransferRequest) (*TransferResponse, *RawResponse, error) {
+	var raw RawResponse
+	resp, err := r.BankFundsTransferContext(WithRawResponse(context.Background(), &raw), req)
+	return resp, &raw, err
+}
+
+//WalletFundsTransferRaw is like WalletFundsTransfer but also returns the raw response
+func (r *Client) WalletFundsTransferRaw(recipientPhone string, amount float64, narration, reference string) (*TransferResponse, *RawResponse, error) {
+	var raw RawResponse
+	resp, err := r.WalletFundsTransferContext(WithRawResponse(context.Background(), &raw), recipientPhone, amount, narration, reference)
+	return resp, &raw, err
+}
+
+//PurchaseAirtimeRaw is like PurchaseAirtime but also returns the raw response
+func (r *Client) PurchaseAirtimeRaw(phone string, amount float64, network, reference string) (*AirtimeResponse, *RawResponse, error) {
+	var raw RawResponse
+	resp, err := r.PurchaseAirtimeContext(WithRawResponse(context.Background(), &raw), phone, amount, network, reference)
+	return resp, &raw, err
+}
+
+//CheckTransactionRaw is like CheckTransaction but also returns the raw response
+func (r *Client) CheckTransactionRaw(transactionRef string) (*TransactionStatusResponse, *RawResponse, error) {
+	var raw RawResponse
+	resp, err := r.CheckTransactionContext(WithRawResponse(context.Background(), &raw), transactionRef)
+	return resp, &raw, err
+}
@@ -0,0 +1,69 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawResponseCapturesBody(t *testing.T) {
+
+	ussdResponse := `{"merchantRef": "0000000000011715", "status": "AWAITING CUSTOMER", "vendorField": "kept"}`
+	balanceError := `{"Status": 400, "Code": 400, "Message": "bad request"}`
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseUssdTransaction {
+			rw.Header().Add("content-type", "application/json")
+			rw.Header().Add("x-request-id", "req-123")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(ussdResponse))
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte(balanceError))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, raw, err := apiClient.GenerateUSSDRaw("user-defined-ref", 1000, "044")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, "0000000000011715", resp.MerchantRef)
+	assert.Equal(t, http.StatusOK, raw.StatusCode)
+	assert.Equal(t, ussdResponse, string(raw.Body))
+	assert.Equal(t, "req-123", raw.Header.Get("x-request-id"))
+
+	_, raw, err = apiClient.BalanceEnquiryRaw()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, raw.StatusCode)
+	assert.Equal(t, balanceError, string(raw.Body))
+
+	var captured RawResponse
+	_, err = apiClient.FetchTransactionContext(WithRawResponse(context.Background(), &captured), nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, captured.StatusCode)
+	assert.Equal(t, "OK", string(captured.Body))
+}
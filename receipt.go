@@ -0,0 +1,143 @@
+package readycash
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	receiptWidth      = 32
+	receiptLabelWidth = 11
+	receiptDateLayout = "2006-01-02 15:04"
+)
+
+//FormatReceipt renders the transaction as a fixed width text block for
+//thermal receipt printers
+func (w *WalletTransaction) FormatReceipt() string {
+	date := w.Time().UTC()
+	if formatted, err := time.Parse(time.RFC3339, w.FormattedDate); err == nil {
+		date = formatted
+	}
+
+	reference := w.Reciept.Reference
+	if reference == "" {
+		reference = strconv.FormatInt(w.TranID, 10)
+	}
+
+	var b receiptBuilder
+	b.header("TRANSACTION RECEIPT")
+	b.line("Date", date.Format(receiptDateLayout))
+	b.line("Reference", reference)
+	b.line("Amount", "NGN "+formatAmount(w.Amount))
+	b.line("Narration", w.Narration)
+	b.line("Terminal", w.PosTerminalID)
+	b.rule()
+	return b.String()
+}
+
+//FormatReceipt renders the ussd payment as a fixed width text block for
+//thermal receipt printers. Responses returned by the client give the date
+//in the WithLocation zone, like wallet receipts, others in UTC
+func (r *UssdTransactionResponse) FormatReceipt() string {
+	loc := r.location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var b receiptBuilder
+	b.header("USSD PAYMENT RECEIPT")
+	b.line("Date", r.TransactionTime().In(loc).Format(receiptDateLayout))
+	b.line("Reference", r.TransactionRef)
+	b.line("Merchant", r.MerchantRef)
+	b.line("Amount", "NGN "+formatAmount(r.Amount))
+	b.line("USSD", r.UssdString)
+	b.line("Status", r.Status)
+	b.rule()
+	return b.String()
+}
+
+type receiptBuilder struct {
+	strings.Builder
+}
+
+func (b *receiptBuilder) rule() {
+	b.WriteString(strings.Repeat("=", receiptWidth) + "\n")
+}
+
+func (b *receiptBuilder) header(title string) {
+	b.rule()
+	padding := (receiptWidth - len(title)) / 2
+	if padding < 0 {
+		padding = 0
+	}
+	b.WriteString(strings.TrimRight(strings.Repeat(" ", padding)+title, " ") + "\n")
+	b.rule()
+}
+
+//line writes a label and value, wrapping values too long for one line under
+//the value column. Empty values are left out
+func (b *receiptBuilder) line(label, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return
+	}
+
+	valueWidth := receiptWidth - receiptLabelWidth
+	for i, chunk := range wrapReceiptValue(value, valueWidth) {
+		if i > 0 {
+			label = ""
+		}
+		b.WriteString(fmt.Sprintf("%-*s%s\n", receiptLabelWidth, label, chunk))
+	}
+}
+
+func wrapReceiptValue(value string, width int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(value) {
+		for len(word) > width {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+//formatAmount formats amount with two decimals and thousands separators
+func formatAmount(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	cents := int64(math.Round(amount * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, grouped.String(), cents%100)
+}
@@ -0,0 +1,94 @@
+package readycash
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func assertGolden(t *testing.T, name, actual string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("Did not expect writing golden file to fail: %v", err)
+		}
+	}
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Did not expect reading golden file to fail: %v", err)
+	}
+	assert.Equal(t, string(expected), actual)
+}
+
+func TestWalletTransactionFormatReceipt(t *testing.T) {
+
+	transactions, err := NewWalletTransactions([]byte(`[{
+		"tranId": 32101362,
+		"tranType": "200.21.0001",
+		"narration": "AGENT POS CASHBACK 2058LS41 000000123456 payment for school fees and uniforms",
+		"longDescription": "Money deposited using terminal 2058LS41",
+		"date": 1622307120000,
+		"amount": 1234567.5,
+		"reciept": {"amount": 1234567.5, "date": 1622307120000, "reference": "0000000000001070108"}
+	}]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assertGolden(t, "wallet_receipt.golden", transactions[0].FormatReceipt())
+}
+
+func TestUssdTransactionFormatReceipt(t *testing.T) {
+
+	resp, err := NewUssdTransactionResponse([]byte(`{
+		"merchantRef": "0000000000011715",
+		"transactionRef": "0000000000001070108",
+		"ussdString": "*901*000*1111#",
+		"amount": 992.5,
+		"transactionDate": 1622307058834,
+		"status": "SUCCESSFUL"
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assertGolden(t, "ussd_receipt.golden", resp.FormatReceipt())
+}
+
+func TestReceiptsUseClientLocation(t *testing.T) {
+
+	lagos := time.FixedZone("WAT", 60*60)
+
+	transactions, err := NewWalletTransactionsIn([]byte(`[{"tranId": 1, "date": 1622307120000}]`), lagos)
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Contains(t, transactions[0].FormatReceipt(), "Date       2021-05-29 17:52\n")
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil,
+		WithDryRun(true), WithLocation(lagos), WithClock(&fakeClock{now: time.Unix(1622307058, 0)}))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	resp, err := apiClient.GenerateUSSD("ussd-ref", 1000, "044")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Contains(t, resp.FormatReceipt(), "Date       2021-05-29 17:50\n")
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "0.00", formatAmount(0))
+	assert.Equal(t, "999.99", formatAmount(999.99))
+	assert.Equal(t, "1,000.00", formatAmount(1000))
+	assert.Equal(t, "1,234,567.50", formatAmount(1234567.5))
+	assert.Equal(t, "-12,500.05", formatAmount(-12500.05))
+}
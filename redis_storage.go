@@ -0,0 +1,64 @@
+package readycash
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+//RedisStorage is a Storage backed by redis so several client instances can
+//share one login session
+type RedisStorage struct {
+	client *redis.Client
+	prefix string
+}
+
+//NewRedisStorage returns a Storage that namespaces every key with prefix
+func NewRedisStorage(client *redis.Client, prefix string) *RedisStorage {
+	return &RedisStorage{client: client, prefix: prefix}
+}
+
+func (s *RedisStorage) SetStringFor(key, val string, exp time.Duration) error {
+	return s.SetStringForContext(context.Background(), key, val, exp)
+}
+
+func (s *RedisStorage) SetIntFor(key string, val int64, exp time.Duration) error {
+	return s.SetIntForContext(context.Background(), key, val, exp)
+}
+
+func (s *RedisStorage) GetString(key string) (string, error) {
+	return s.GetStringContext(context.Background(), key)
+}
+
+func (s *RedisStorage) GetInt(key string) (int64, error) {
+	return s.GetIntContext(context.Background(), key)
+}
+
+func (s *RedisStorage) Delete(key string) error {
+	return s.DeleteContext(context.Background(), key)
+}
+
+func (s *RedisStorage) SetStringForContext(ctx context.Context, key, val string, exp time.Duration) error {
+	return s.client.Set(ctx, s.key(key), val, exp).Err()
+}
+
+func (s *RedisStorage) SetIntForContext(ctx context.Context, key string, val int64, exp time.Duration) error {
+	return s.client.Set(ctx, s.key(key), val, exp).Err()
+}
+
+func (s *RedisStorage) GetStringContext(ctx context.Context, key string) (string, error) {
+	return s.client.Get(ctx, s.key(key)).Result()
+}
+
+func (s *RedisStorage) GetIntContext(ctx context.Context, key string) (int64, error) {
+	return s.client.Get(ctx, s.key(key)).Int64()
+}
+
+func (s *RedisStorage) DeleteContext(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
+
+func (s *RedisStorage) key(key string) string {
+	return s.prefix + key
+}
@@ -0,0 +1,98 @@
+package readycash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedisStorage(t *testing.T, prefix string) (*RedisStorage, *miniredis.Miniredis) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Did not expect miniredis to fail: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisStorage(client, prefix), server
+}
+
+func TestRedisStorageRoundTrip(t *testing.T) {
+
+	storage, server := newTestRedisStorage(t, "readycash:")
+
+	assert.NoError(t, storage.SetStringFor("auth-token", "Bearer Token", time.Minute))
+	assert.NoError(t, storage.SetIntFor("auth-expiration", 1622307120, time.Minute))
+
+	token, err := storage.GetString("auth-token")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer Token", token)
+
+	expiration, err := storage.GetInt("auth-expiration")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1622307120), expiration)
+
+	assert.True(t, server.Exists("readycash:auth-token"))
+	assert.False(t, server.Exists("auth-token"))
+	assert.Equal(t, time.Minute, server.TTL("readycash:auth-token"))
+
+	server.FastForward(2 * time.Minute)
+	_, err = storage.GetString("auth-token")
+	assert.Error(t, err)
+}
+
+func TestRedisStorageDelete(t *testing.T) {
+
+	storage, server := newTestRedisStorage(t, "readycash:")
+
+	assert.NoError(t, storage.SetStringFor("auth-token", "Bearer Token", time.Minute))
+	assert.NoError(t, storage.Delete("auth-token"))
+	assert.False(t, server.Exists("readycash:auth-token"))
+
+	_, err := storage.GetString("auth-token")
+	assert.ErrorIs(t, err, redis.Nil)
+	assert.NoError(t, storage.Delete("auth-token"))
+}
+
+func TestRedisStorageHonoursContext(t *testing.T) {
+
+	storage, _ := newTestRedisStorage(t, "readycash:")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, storage.SetStringForContext(ctx, "auth-token", "Bearer Token", time.Minute), context.Canceled)
+	_, err := storage.GetStringContext(ctx, "auth-token")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRedisStorageWithClient(t *testing.T) {
+
+	storage, _ := newTestRedisStorage(t, "readycash:")
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, "http://localhost", storage, nil)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	session, err := authParams{
+		authorization: "Bearer Token",
+		sessionID:     "1234",
+		encodedPin:    "encoded-pin",
+		expiration:    time.Now().Add(time.Hour),
+	}.marshal()
+	if err != nil {
+		t.Fatalf("Did not expect marshalling to fail: %v", err)
+	}
+	assert.NoError(t, storage.SetStringFor(apiClient.makeAuthCacheKeys().sessionKey, session, time.Hour))
+
+	assert.NoError(t, apiClient.ensureUserIsAuthenticated(context.Background()))
+	assert.Equal(t, "Bearer Token", apiClient.session().authorization)
+}
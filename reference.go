@@ -0,0 +1,51 @@
+package readycash
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//MaxReferenceLength is the longest reference the gateway accepts for ussd
+//generation, transfers and airtime purchases
+const MaxReferenceLength = 30
+
+//the generated part of a reference is the time and a random number, each
+//written as 8 base 36 digits
+const (
+	referenceTimeLength   = 8
+	referenceRandomLength = 8
+	referenceRandomSpace  = 2821109907456
+)
+
+//GenerateReference returns prefix followed by the current time in
+//milliseconds and 8 random characters, all in upper case base 36. The
+//16 generated characters sort by time, and prefix is cut short when needed
+//to keep the reference within MaxReferenceLength
+func (r *Client) GenerateReference(prefix string) string {
+	maxPrefix := MaxReferenceLength - referenceTimeLength - referenceRandomLength
+	if len(prefix) > maxPrefix {
+		prefix = prefix[:maxPrefix]
+	}
+
+	millis := r.clock.Now().UnixNano() / int64(time.Millisecond)
+	return prefix + padBase36(uint64(millis), referenceTimeLength) + padBase36(randomUint64()%referenceRandomSpace, referenceRandomLength)
+}
+
+func padBase36(v uint64, width int) string {
+	s := strings.ToUpper(strconv.FormatUint(v, 36))
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
+
+func randomUint64() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return uint64(time.Now().UnixNano())
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
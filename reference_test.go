@@ -0,0 +1,50 @@
+package readycash
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateReference(t *testing.T) {
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		reference := apiClient.GenerateReference("PAY-")
+		assert.True(t, strings.HasPrefix(reference, "PAY-"))
+		assert.Len(t, reference, len("PAY-")+16)
+		assert.False(t, seen[reference], "duplicate reference %s", reference)
+		seen[reference] = true
+	}
+
+	long := apiClient.GenerateReference(strings.Repeat("X", 40))
+	assert.Len(t, long, MaxReferenceLength)
+	assert.True(t, strings.HasPrefix(long, strings.Repeat("X", MaxReferenceLength-16)))
+
+	assert.Len(t, apiClient.GenerateReference(""), 16)
+}
+
+func TestGenerateReferenceSortsByTime(t *testing.T) {
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	var references []string
+	for i := 0; i < 3; i++ {
+		references = append(references, apiClient.GenerateReference("REF"))
+		time.Sleep(2 * time.Millisecond)
+	}
+	assert.True(t, sort.StringsAreSorted(references))
+}
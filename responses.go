@@ -10,15 +10,20 @@ import (
 )
 
 var (
-	terminalIDRegex       = regexp.MustCompile(`(?mi)[Mm]oney\s+deposited\s+using\s+terminal\s+(.+)?\s*`)
-	posTransactionIDRegex = regexp.MustCompile(`(?mi)AGENT\s+POS\s+CASHBACK.*\s+(.+)?\s*`)
+	terminalIDRegex       = regexp.MustCompile(`(?i)money\s+deposited\s+using\s+terminal\s+([A-Za-z0-9]+)\b`)
+	posTransactionIDRegex = regexp.MustCompile(`(?i)AGENT\s+POS\s+CASHBACK\s+(?:([A-Za-z0-9]+)\s+)?(\d+)(?:\s|$)`)
 )
 
+//ErrorResponse is returned for api calls that do not succeed. HTTPStatus and
+//RawBody hold the http status code and body as received, Status and Code
+//are whatever the body reported
 type ErrorResponse struct {
 	Status           int
 	Code             int
 	Message          string
 	DeveloperMessage string
+	HTTPStatus       int    `json:"-"`
+	RawBody          []byte `json:"-"`
 }
 
 func NewErrorResponse(status int, code int, message string, developerMessage string) *ErrorResponse {
@@ -33,9 +38,60 @@ func (e *ErrorResponse) Error() string {
 	return fmt.Sprintf("Code: %d, Message: %s, Status: %d", e.Code, e.Message, e.Status)
 }
 
+//Is reports whether target is the sentinel error mapped from e.Code, like
+//ErrInsufficientFunds, or an *ErrorResponse whose non zero HTTPStatus,
+//Status, Code and Message all match e, so errors.Is(err,
+//&ErrorResponse{HTTPStatus: http.StatusUnauthorized}) matches any 401
+func (e *ErrorResponse) Is(target error) bool {
+	if sentinel, ok := apiErrorCodes[e.Code]; ok && sentinel == target {
+		return true
+	}
+
+	t, ok := target.(*ErrorResponse)
+	if !ok {
+		return false
+	}
+	return (t.HTTPStatus == 0 || t.HTTPStatus == e.HTTPStatus) &&
+		(t.Status == 0 || t.Status == e.Status) &&
+		(t.Code == 0 || t.Code == e.Code) &&
+		(t.Message == "" || t.Message == e.Message)
+}
+
+//LoginResult is the body returned by the login endpoint, FirstTime is set
+//when the account still has to complete its first time setup
+type LoginResult struct {
+	FirstTime bool `json:"first_time"`
+	//SessionLength is the session length in seconds the api granted, it is
+	//zero when the api did not say
+	SessionLength int64 `json:"session_length,omitempty"`
+}
+
+func NewLoginResult(data []byte) (*LoginResult, error) {
+	var r LoginResult
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *LoginResult) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewLoginResultFromJSON(data []byte) (*LoginResult, error) {
+	return NewLoginResult(data)
+}
+
 type BalanceEnquiryResponse struct {
 	Income float64 `json:"income"`
 	Main   float64 `json:"main"`
+	//Others holds the numeric balances the api returned besides income and
+	//main, keyed by their name
+	Others map[string]float64 `json:"others,omitempty"`
+}
+
+//Total returns the sum of the Income and Main balances, Others is left out
+//as what those buckets hold is not known
+func (r *BalanceEnquiryResponse) Total() float64 {
+	return r.Income + r.Main
 }
 
 func NewBalanceResponse(data []byte) (*BalanceEnquiryResponse, error) {
@@ -46,42 +102,87 @@ func NewBalanceResponse(data []byte) (*BalanceEnquiryResponse, error) {
 
 	var r BalanceEnquiryResponse
 
-	if incomeStr, ok := balanceMap["income"]; ok {
-		incomeFloat, err := strconv.ParseFloat(incomeStr.(string), 64)
+	if incomeValue, ok := balanceMap["income"]; ok {
+		incomeFloat, err := parseBalanceValue("income", incomeValue)
 		if err != nil {
 			return nil, err
 		}
 		r.Income = incomeFloat
 	}
 
-	if mainStr, ok := balanceMap["main"]; ok {
-		mainFloat, err := strconv.ParseFloat(mainStr.(string), 64)
+	if mainValue, ok := balanceMap["main"]; ok {
+		mainFloat, err := parseBalanceValue("main", mainValue)
 		if err != nil {
 			return nil, err
 		}
 		r.Main = mainFloat
 	}
 
+	for name, value := range balanceMap {
+		if name == "income" || name == "main" {
+			continue
+		}
+		otherFloat, err := parseBalanceValue(name, value)
+		if err != nil {
+			continue
+		}
+		if r.Others == nil {
+			r.Others = map[string]float64{}
+		}
+		r.Others[name] = otherFloat
+	}
+
 	return &r, nil
 }
 
+func (r *BalanceEnquiryResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+//NewBalanceEnquiryResponseFromJSON decodes a balance produced by Marshal
+func NewBalanceEnquiryResponseFromJSON(data []byte) (*BalanceEnquiryResponse, error) {
+	var r BalanceEnquiryResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+//parseBalanceValue accepts balances sent either as json numbers or as
+//numeric strings, a null balance is treated as zero
+func parseBalanceValue(name string, value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("unexpected type %T for %s balance", value, name)
+	}
+}
+
 //UssdTransactionResponse returned from generate ussd operation
 type UssdTransactionResponse struct {
-	UserDefinedReference string  `json:"userDefinedReference"`
-	MerchantRef          string  `json:"merchantRef"`
-	TransactionRef       string  `json:"transactionRef"`
-	UssdString           string  `json:"ussdString"`
-	Amount               int64   `json:"amount"`
-	ResponseCode         string  `json:"responseCode"`
-	TransactionDate      int64   `json:"transactionDate"`
-	ExpiryDate           int64   `json:"expiryDate"`
-	CompletionDate       int64   `json:"completionDate"`
-	Status               string  `json:"status"`
-	PaymentRef           *string `json:"paymentRef"`
-	PayerPhone           *string `json:"payerPhone"`
-	PaymentBank          *string `json:"paymentBank"`
-	PaymentNetwork       *string `json:"paymentNetwork"`
-	PaymentBankCode      *string `json:"paymentBankCode"`
+	UserDefinedReference string          `json:"userDefinedReference"`
+	MerchantRef          string          `json:"merchantRef"`
+	TransactionRef       string          `json:"transactionRef"`
+	UssdString           string          `json:"ussdString"`
+	Amount               float64         `json:"amount"`
+	Fee                  float64         `json:"fee"`
+	ResponseCode         string          `json:"responseCode"`
+	TransactionDate      int64           `json:"transactionDate"`
+	ExpiryDate           int64           `json:"expiryDate"`
+	CompletionDate       int64           `json:"completionDate"`
+	Status               string          `json:"status"`
+	PaymentRef           *string         `json:"paymentRef"`
+	PayerPhone           *string         `json:"payerPhone"`
+	PaymentBank          *string         `json:"paymentBank"`
+	PaymentNetwork       *string         `json:"paymentNetwork"`
+	PaymentBankCode      *string         `json:"paymentBankCode"`
+	ThirdParty           *ThirdPartyInfo `json:"thirdParty"`
+
+	clock    Clock
+	location *time.Location
 }
 
 func NewUssdTransactionResponse(data []byte) (*UssdTransactionResponse, error) {
@@ -90,56 +191,186 @@ func NewUssdTransactionResponse(data []byte) (*UssdTransactionResponse, error) {
 	return &r, err
 }
 
+func NewUssdTransactionResponseFromJSON(data []byte) (*UssdTransactionResponse, error) {
+	return NewUssdTransactionResponse(data)
+}
+
 func (r *UssdTransactionResponse) Marshal() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+//MarshalRequest encodes r for sending to the api. Unlike Marshal, which keeps
+//everything for storage, it leaves out UserDefinedReference as the api never
+//sends it and does not expect it back
+func (r *UssdTransactionResponse) MarshalRequest() ([]byte, error) {
+	type ussdTransaction UssdTransactionResponse
+	return json.Marshal(struct {
+		*ussdTransaction
+		UserDefinedReference string `json:"userDefinedReference,omitempty"`
+	}{ussdTransaction: (*ussdTransaction)(r)})
+}
+
+//TransactionTime returns TransactionDate as a time.Time
+func (r *UssdTransactionResponse) TransactionTime() time.Time {
+	return millisToTime(r.TransactionDate)
+}
+
+//ExpiryTime returns ExpiryDate as a time.Time
+func (r *UssdTransactionResponse) ExpiryTime() time.Time {
+	return millisToTime(r.ExpiryDate)
+}
+
+//TimeUntilExpiry returns how long the ussd code stays valid, it is zero or
+//negative once the code has expired and zero when there is no ExpiryDate.
+//Responses returned by the client use its clock
+func (r *UssdTransactionResponse) TimeUntilExpiry() time.Duration {
+	if r.ExpiryDate == 0 {
+		return 0
+	}
+	clock := r.clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	return r.ExpiryTime().Sub(clock.Now())
+}
+
+//CompletionTime returns CompletionDate as a time.Time, it is zero while the transaction is pending
+func (r *UssdTransactionResponse) CompletionTime() time.Time {
+	return millisToTime(r.CompletionDate)
+}
+
 type Reciept struct {
-	Amount            float64 `json:"amount"`
-	Date              int64   `json:"date"`
-	Reference         string  `json:"reference"`
-	Recipient         string  `json:"recipient"`
-	TranType          string  `json:"tranType,omitempty"`
-	ExternalReference string  `json:"externalReference,omitempty"`
-	Bank              string  `json:"bank,omitempty"`
-	Account           string  `json:"account,omitempty"`
-	Name              string  `json:"name,omitempty"`
-	Narration         string  `json:"narration,omitempty"`
-	FormattedDate     string  `json:"formatted_date,omitempty"`
+	Amount            Money  `json:"amount"`
+	Date              int64  `json:"date"`
+	Reference         string `json:"reference"`
+	Recipient         string `json:"recipient"`
+	TranType          string `json:"tranType,omitempty"`
+	ExternalReference string `json:"externalReference,omitempty"`
+	Bank              string `json:"bank,omitempty"`
+	Account           string `json:"account,omitempty"`
+	Name              string `json:"name,omitempty"`
+	Narration         string `json:"narration,omitempty"`
+	FormattedDate     string `json:"formatted_date,omitempty"`
+}
+
+//Time returns the receipt Date as a time.Time
+func (r Reciept) Time() time.Time {
+	return millisToTime(r.Date)
 }
 
 type WalletTransaction struct {
-	Debit            bool    `json:"debit"`
-	TranID           int64   `json:"tranId"`
-	TranType         string  `json:"tranType"`
-	Description      string  `json:"description"`
-	ShortDescription string  `json:"shortDescription"`
-	Narration        string  `json:"narration"`
-	LongDescription  string  `json:"longDescription"`
-	Date             int64   `json:"date"`
-	Amount           float64 `json:"amount"`
-	Reciept          Reciept `json:"reciept"`
-	Balance          float64 `json:"balance"`
-	Balance2         float64 `json:"balance2"`
-	LogoID           string  `json:"logoId"`
-	PosTerminalID    string  `json:"pos_terminal_id"`
-	PosTransactionID string  `json:"pos_transaction_id"`
-	FormattedDate    string  `json:"formatted_date"`
+	Debit            bool            `json:"debit"`
+	TranID           int64           `json:"tranId"`
+	TranType         string          `json:"tranType"`
+	Description      string          `json:"description"`
+	ShortDescription string          `json:"shortDescription"`
+	Narration        string          `json:"narration"`
+	LongDescription  string          `json:"longDescription"`
+	Date             int64           `json:"date"`
+	Amount           float64         `json:"amount"`
+	Fee              float64         `json:"fee"`
+	Reciept          Reciept         `json:"reciept"`
+	Balance          float64         `json:"balance"`
+	Balance2         float64         `json:"balance2"`
+	LogoID           string          `json:"logoId"`
+	PosTerminalID    string          `json:"pos_terminal_id"`
+	PosTransactionID string          `json:"pos_transaction_id"`
+	FormattedDate    string          `json:"formatted_date"`
+	Reversed         bool            `json:"reversed"`
+	Provider         string          `json:"provider,omitempty"`
+	ThirdParty       *ThirdPartyInfo `json:"thirdParty"`
 }
 
+//ThirdPartyInfo describes the integration, like Schoolable, a transaction
+//came through. It is nil when the transaction has no third party
+type ThirdPartyInfo struct {
+	Provider  string `json:"provider"`
+	Reference string `json:"reference,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+//UnmarshalJSON accepts either the full object or just the provider name
+func (t *ThirdPartyInfo) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = ThirdPartyInfo{Provider: name}
+		return nil
+	}
+
+	type thirdPartyInfo ThirdPartyInfo
+	var info thirdPartyInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+	*t = ThirdPartyInfo(info)
+	return nil
+}
+
+//IsReversed reports whether this is the reversal of an earlier transaction
+func (w *WalletTransaction) IsReversed() bool {
+	return w.TranType == reversedTransactionType
+}
+
+//Time returns the transaction Date as a time.Time
+func (w *WalletTransaction) Time() time.Time {
+	return millisToTime(w.Date)
+}
+
+//IsSchoolable reports whether the transaction came through the Schoolable
+//school fees integration
+func (w *WalletTransaction) IsSchoolable() bool {
+	return w.Provider == providerSchoolable
+}
+
+//detectProvider takes the provider from the thirdParty field, which is
+//either the provider name or an object holding it. Older transactions only
+//mention Schoolable in their descriptions
+func (w *WalletTransaction) detectProvider() {
+	if w.ThirdParty != nil {
+		w.Provider = strings.ToUpper(strings.TrimSpace(w.ThirdParty.Provider))
+	}
+
+	if w.Provider != "" {
+		return
+	}
+	for _, text := range []string{w.Description, w.Narration, w.LongDescription} {
+		if strings.Contains(strings.ToUpper(text), providerSchoolable) {
+			w.Provider = providerSchoolable
+			return
+		}
+	}
+}
+
+//detectPosTerminalAndTransactionID reads the terminal id from descriptions
+//like "Money deposited using terminal 2058LS41" and the transaction id from
+//narrations like "AGENT POS CASHBACK 2058LS41 000001", where the terminal
+//token is optional. Fields are left alone when nothing matches
 func (w *WalletTransaction) detectPosTerminalAndTransactionID() {
-	terminalIDResult := terminalIDRegex.FindStringSubmatch(strings.TrimSpace(w.LongDescription))
-	if len(terminalIDResult) > 1 {
+	terminalIDResult := terminalIDRegex.FindStringSubmatch(w.LongDescription)
+	if len(terminalIDResult) > 1 && terminalIDResult[1] != "" {
 		w.PosTerminalID = terminalIDResult[1]
 	}
 
-	posTransactionIDResult := posTransactionIDRegex.FindStringSubmatch(strings.TrimSpace(w.Narration))
-	if len(posTransactionIDResult) > 1 {
-		w.PosTransactionID = posTransactionIDResult[1]
+	posTransactionIDResult := posTransactionIDRegex.FindStringSubmatch(w.Narration)
+	if len(posTransactionIDResult) > 2 {
+		if w.PosTerminalID == "" && posTransactionIDResult[1] != "" {
+			w.PosTerminalID = posTransactionIDResult[1]
+		}
+		w.PosTransactionID = posTransactionIDResult[2]
 	}
 }
 
+//NewWalletTransactions parses a transaction list, FormattedDate is given in UTC
 func NewWalletTransactions(data []byte) ([]WalletTransaction, error) {
+	return NewWalletTransactionsIn(data, time.UTC)
+}
+
+//NewWalletTransactionsIn is like NewWalletTransactions but gives FormattedDate in loc
+func NewWalletTransactionsIn(data []byte, loc *time.Location) ([]WalletTransaction, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
 	var walletTransactions []WalletTransaction
 	if err := json.Unmarshal(data, &walletTransactions); err != nil {
 		return nil, err
@@ -149,10 +380,236 @@ func NewWalletTransactions(data []byte) ([]WalletTransaction, error) {
 	for _, transaction := range walletTransactions {
 		t := transaction
 		t.detectPosTerminalAndTransactionID()
-		t.FormattedDate = time.Unix(t.Date/1000, 0).Format(time.RFC3339)
-		t.Reciept.FormattedDate = time.Unix(t.Reciept.Date/1000, 0).Format(time.RFC3339)
+		t.detectProvider()
+		t.Reversed = t.IsReversed()
+		t.FormattedDate = time.Unix(t.Date/1000, 0).In(loc).Format(time.RFC3339)
+		t.Reciept.FormattedDate = time.Unix(t.Reciept.Date/1000, 0).In(loc).Format(time.RFC3339)
 		result = append(result, t)
 	}
 
 	return result, nil
 }
+
+func (w *WalletTransaction) Marshal() ([]byte, error) {
+	return json.Marshal(w)
+}
+
+//NewWalletTransactionFromJSON decodes a single transaction produced by
+//Marshal, the derived fields are kept as they were encoded
+func NewWalletTransactionFromJSON(data []byte) (*WalletTransaction, error) {
+	var w WalletTransaction
+	err := json.Unmarshal(data, &w)
+	return &w, err
+}
+
+//NameEnquiryResponse returned from the name enquiry operation
+type NameEnquiryResponse struct {
+	AccountName   string `json:"accountName"`
+	AccountNumber string `json:"accountNumber"`
+	BankCode      string `json:"bankCode"`
+}
+
+func NewNameEnquiryResponse(data []byte) (*NameEnquiryResponse, error) {
+	var r NameEnquiryResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *NameEnquiryResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewNameEnquiryResponseFromJSON(data []byte) (*NameEnquiryResponse, error) {
+	return NewNameEnquiryResponse(data)
+}
+
+//TransferResponse returned from the bank and wallet transfer operations.
+//Amount is taken from the request when the api leaves it out
+type TransferResponse struct {
+	TransactionRef string  `json:"transactionRef"`
+	Status         string  `json:"status"`
+	Amount         float64 `json:"amount"`
+	Fee            float64 `json:"fee"`
+}
+
+//NetAmount returns the Amount less the Fee charged on the transfer
+func (r *TransferResponse) NetAmount() float64 {
+	return r.Amount - r.Fee
+}
+
+func NewTransferResponse(data []byte) (*TransferResponse, error) {
+	var r TransferResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *TransferResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewTransferResponseFromJSON(data []byte) (*TransferResponse, error) {
+	return NewTransferResponse(data)
+}
+
+//AirtimeResponse returned from the airtime purchase operation
+type AirtimeResponse struct {
+	TransactionRef string  `json:"transactionRef"`
+	Status         string  `json:"status"`
+	Amount         float64 `json:"amount"`
+	Fee            float64 `json:"fee"`
+}
+
+//NetAmount returns the Amount less the Fee charged on the purchase
+func (r *AirtimeResponse) NetAmount() float64 {
+	return r.Amount - r.Fee
+}
+
+func NewAirtimeResponse(data []byte) (*AirtimeResponse, error) {
+	var r AirtimeResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *AirtimeResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewAirtimeResponseFromJSON(data []byte) (*AirtimeResponse, error) {
+	return NewAirtimeResponse(data)
+}
+
+//VirtualAccountResponse returned from the virtual account creation operation
+type VirtualAccountResponse struct {
+	AccountNumber    string `json:"accountNumber"`
+	BankName         string `json:"bankName"`
+	AccountReference string `json:"accountReference"`
+}
+
+func NewVirtualAccountResponse(data []byte) (*VirtualAccountResponse, error) {
+	var r VirtualAccountResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *VirtualAccountResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewVirtualAccountResponseFromJSON(data []byte) (*VirtualAccountResponse, error) {
+	return NewVirtualAccountResponse(data)
+}
+
+//CreateAgentResponse returned from the agent creation operation
+type CreateAgentResponse struct {
+	AgentID string `json:"agentId"`
+	Status  string `json:"status"`
+}
+
+func NewCreateAgentResponse(data []byte) (*CreateAgentResponse, error) {
+	var r CreateAgentResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *CreateAgentResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewCreateAgentResponseFromJSON(data []byte) (*CreateAgentResponse, error) {
+	return NewCreateAgentResponse(data)
+}
+
+//RegisterUserResponse returned from the user registration operation
+type RegisterUserResponse struct {
+	WalletID string `json:"walletId"`
+	Phone    string `json:"phone"`
+	Status   string `json:"status"`
+}
+
+func NewRegisterUserResponse(data []byte) (*RegisterUserResponse, error) {
+	var r RegisterUserResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *RegisterUserResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewRegisterUserResponseFromJSON(data []byte) (*RegisterUserResponse, error) {
+	return NewRegisterUserResponse(data)
+}
+
+//TransactionStatusResponse returned from the check transaction operation
+type TransactionStatusResponse struct {
+	TransactionRef  string  `json:"transactionRef"`
+	TranType        string  `json:"tranType"`
+	Amount          float64 `json:"amount"`
+	ResponseCode    string  `json:"responseCode"`
+	Status          string  `json:"status"`
+	TransactionDate int64   `json:"transactionDate"`
+	CompletionDate  int64   `json:"completionDate"`
+}
+
+func NewTransactionStatusResponse(data []byte) (*TransactionStatusResponse, error) {
+	var r TransactionStatusResponse
+	err := json.Unmarshal(data, &r)
+	return &r, err
+}
+
+func (r *TransactionStatusResponse) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func NewTransactionStatusResponseFromJSON(data []byte) (*TransactionStatusResponse, error) {
+	return NewTransactionStatusResponse(data)
+}
+
+//TransactionTime returns TransactionDate as a time.Time
+func (r *TransactionStatusResponse) TransactionTime() time.Time {
+	return millisToTime(r.TransactionDate)
+}
+
+//CompletionTime returns CompletionDate as a time.Time, it is zero while the transaction is pending
+func (r *TransactionStatusResponse) CompletionTime() time.Time {
+	return millisToTime(r.CompletionDate)
+}
+
+//Bank is an institution supported by the api
+type Bank struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+func NewBanks(data []byte) ([]Bank, error) {
+	var banks []Bank
+	if err := json.Unmarshal(data, &banks); err != nil {
+		return nil, err
+	}
+	return banks, nil
+}
+
+func (b *Bank) Marshal() ([]byte, error) {
+	return json.Marshal(b)
+}
+
+//NewBankFromJSON decodes a single bank produced by Marshal
+func NewBankFromJSON(data []byte) (*Bank, error) {
+	var b Bank
+	err := json.Unmarshal(data, &b)
+	return &b, err
+}
+
+//millisToTime converts the epoch milliseconds used by the api into a
+//time.Time, zero maps to the zero time rather than the unix epoch
+func millisToTime(millis int64) time.Time {
+	if millis == 0 {
+		return time.Time{}
+	}
+	return time.Unix(millis/1000, (millis%1000)*int64(time.Millisecond))
+}
+
+//timeToMillis converts t into the epoch milliseconds used by the api
+func timeToMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,506 @@
+package readycash
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUssdTransactionResponseFractionalAmount(t *testing.T) {
+
+	resp, err := NewUssdTransactionResponse([]byte(`{
+		"merchantRef": "0000000000011715",
+		"amount": 992.50,
+		"status": "SUCCESSFUL"
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, 992.50, resp.Amount)
+
+	resp, err = NewUssdTransactionResponse([]byte(`{"amount": 1000}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, float64(1000), resp.Amount)
+}
+
+func TestNewBalanceResponse(t *testing.T) {
+
+	testCases := []struct {
+		name    string
+		payload string
+		income  float64
+		main    float64
+	}{
+		{"string values", `{"income": "5000.50","main": "1000"}`, 5000.50, 1000},
+		{"number values", `{"income": 5000.50,"main": 1000}`, 5000.50, 1000},
+		{"mixed values", `{"income": "5000.50","main": 1000}`, 5000.50, 1000},
+		{"null value", `{"income": null,"main": "1000"}`, 0, 1000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := NewBalanceResponse([]byte(tc.payload))
+			if err != nil {
+				t.Fatalf("Did not expect parsing to fail: %v", err)
+			}
+			assert.Equal(t, tc.income, resp.Income)
+			assert.Equal(t, tc.main, resp.Main)
+		})
+	}
+
+	_, err := NewBalanceResponse([]byte(`{"income": "not-a-number"}`))
+	assert.Error(t, err)
+
+	_, err = NewBalanceResponse([]byte(`{"income": true}`))
+	assert.Error(t, err)
+}
+
+func TestBalanceResponseOthersAndTotal(t *testing.T) {
+
+	resp, err := NewBalanceResponse([]byte(`{"income": "5000.50","main": 1000,"commission": "250.25","bonus": 10,"currency": "NGN","frozen": null}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assert.Equal(t, map[string]float64{"commission": 250.25, "bonus": 10, "frozen": 0}, resp.Others)
+	assert.Equal(t, 6000.50, resp.Total())
+
+	resp, err = NewBalanceResponse([]byte(`{"income": "0","main": "1000"}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Nil(t, resp.Others)
+	assert.Equal(t, float64(1000), resp.Total())
+
+	data, err := (&BalanceEnquiryResponse{Income: 1, Main: 2, Others: map[string]float64{"commission": 3}}).Marshal()
+	if err != nil {
+		t.Fatalf("Did not expect marshalling to fail: %v", err)
+	}
+	decoded, err := NewBalanceEnquiryResponseFromJSON(data)
+	if err != nil {
+		t.Fatalf("Did not expect decoding to fail: %v", err)
+	}
+	assert.Equal(t, map[string]float64{"commission": 3}, decoded.Others)
+}
+
+func TestUssdTransactionMarshalRequest(t *testing.T) {
+
+	resp, err := NewUssdTransactionResponse([]byte(`{
+		"merchantRef": "0000000000011715",
+		"transactionRef": "0000000000001070108",
+		"amount": 1000,
+		"status": "AWAITING CUSTOMER"
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	resp.UserDefinedReference = "user-defined-ref"
+
+	stored, err := resp.Marshal()
+	if err != nil {
+		t.Fatalf("Did not expect marshalling to fail: %v", err)
+	}
+	var storedFields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(stored, &storedFields))
+	assert.Equal(t, "user-defined-ref", storedFields["userDefinedReference"])
+
+	outbound, err := resp.MarshalRequest()
+	if err != nil {
+		t.Fatalf("Did not expect marshalling to fail: %v", err)
+	}
+	var outboundFields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(outbound, &outboundFields))
+	assert.NotContains(t, outboundFields, "userDefinedReference")
+	assert.Equal(t, "0000000000001070108", outboundFields["transactionRef"])
+	assert.Equal(t, "0000000000011715", outboundFields["merchantRef"])
+	assert.Equal(t, float64(1000), outboundFields["amount"])
+	assert.Equal(t, "user-defined-ref", resp.UserDefinedReference)
+}
+
+func TestTimeUntilExpiry(t *testing.T) {
+
+	clock := newFakeClock()
+	expiry := clock.Now().Add(5 * time.Minute)
+
+	resp := &UssdTransactionResponse{ExpiryDate: expiry.UnixNano() / int64(time.Millisecond), clock: clock}
+
+	assert.Equal(t, 5*time.Minute, resp.TimeUntilExpiry())
+
+	clock.Advance(5 * time.Minute)
+	assert.Equal(t, time.Duration(0), resp.TimeUntilExpiry())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, -time.Minute, resp.TimeUntilExpiry())
+
+	past := &UssdTransactionResponse{ExpiryDate: 1622307350000}
+	assert.True(t, past.TimeUntilExpiry() < 0)
+
+	future := &UssdTransactionResponse{ExpiryDate: time.Now().Add(time.Hour).UnixNano() / int64(time.Millisecond)}
+	assert.True(t, future.TimeUntilExpiry() > 59*time.Minute)
+
+	missing := &UssdTransactionResponse{clock: clock}
+	assert.Equal(t, time.Duration(0), missing.TimeUntilExpiry())
+}
+
+func TestEpochMillisTimeHelpers(t *testing.T) {
+
+	resp, err := NewUssdTransactionResponse([]byte(`{
+		"transactionDate": 1622307059000,
+		"expiryDate": 1622307350001,
+		"completionDate": null
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assert.True(t, time.Date(2021, 5, 29, 16, 50, 59, 0, time.UTC).Equal(resp.TransactionTime()))
+	assert.True(t, time.Date(2021, 5, 29, 16, 55, 50, int(time.Millisecond), time.UTC).Equal(resp.ExpiryTime()))
+	assert.True(t, resp.CompletionTime().IsZero())
+
+	transactions, err := NewWalletTransactions([]byte(`[{
+		"date": 1622307120000,
+		"reciept": {"date": 0}
+	}]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assert.Equal(t, int64(1622307120), transactions[0].Time().Unix())
+	assert.True(t, transactions[0].Reciept.Time().IsZero())
+
+	status := TransactionStatusResponse{TransactionDate: 1622307059000}
+	assert.Equal(t, int64(1622307059), status.TransactionTime().Unix())
+	assert.True(t, status.CompletionTime().IsZero())
+}
+
+func TestNewWalletTransactionsDetectsReversals(t *testing.T) {
+
+	transactions, err := NewWalletTransactions([]byte(`[
+		{
+			"debit": true,
+			"tranId": 32101362,
+			"tranType": "200.22.0000",
+			"description": "Cash IN",
+			"date": 1622290322000,
+			"amount": 4500.00
+		},
+		{
+			"debit": false,
+			"tranId": 32101363,
+			"tranType": "420.00.010.0000",
+			"description": "Reversal",
+			"date": 1622290422000,
+			"amount": 4500.00
+		}
+	]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assert.Len(t, transactions, 2)
+	assert.False(t, transactions[0].IsReversed())
+	assert.False(t, transactions[0].Reversed)
+	assert.True(t, transactions[1].IsReversed())
+	assert.True(t, transactions[1].Reversed)
+}
+
+func TestResponsesRoundTripThroughJSON(t *testing.T) {
+
+	paymentRef := "PAY-1"
+	transactions, err := NewWalletTransactions([]byte(`[{
+		"debit": true,
+		"tranId": 32101362,
+		"tranType": "420.00.010.0000",
+		"longDescription": "Money deposited using terminal 2058LS41",
+		"narration": "AGENT POS CASHBACK 2058LS41 000001",
+		"date": 1626875711000,
+		"amount": 1500.5,
+		"reciept": {"amount": 1500.5, "date": 1626875711000, "reference": "ref"}
+	}]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	roundTrip := func(t *testing.T, value interface{ Marshal() ([]byte, error) }, decode func([]byte) (interface{}, error)) {
+		data, err := value.Marshal()
+		if err != nil {
+			t.Fatalf("Did not expect marshalling to fail: %v", err)
+		}
+		decoded, err := decode(data)
+		if err != nil {
+			t.Fatalf("Did not expect decoding to fail: %v", err)
+		}
+		assert.Equal(t, value, decoded)
+	}
+
+	t.Run("LoginResult", func(t *testing.T) {
+		roundTrip(t, &LoginResult{FirstTime: true}, func(data []byte) (interface{}, error) {
+			return NewLoginResultFromJSON(data)
+		})
+	})
+	t.Run("BalanceEnquiryResponse", func(t *testing.T) {
+		roundTrip(t, &BalanceEnquiryResponse{Income: 5000.25, Main: 1000}, func(data []byte) (interface{}, error) {
+			return NewBalanceEnquiryResponseFromJSON(data)
+		})
+	})
+	t.Run("UssdTransactionResponse", func(t *testing.T) {
+		roundTrip(t, &UssdTransactionResponse{MerchantRef: "0000000000011715", Amount: 992.5, PaymentRef: &paymentRef}, func(data []byte) (interface{}, error) {
+			return NewUssdTransactionResponseFromJSON(data)
+		})
+	})
+	t.Run("WalletTransaction", func(t *testing.T) {
+		roundTrip(t, &transactions[0], func(data []byte) (interface{}, error) {
+			return NewWalletTransactionFromJSON(data)
+		})
+	})
+	t.Run("NameEnquiryResponse", func(t *testing.T) {
+		roundTrip(t, &NameEnquiryResponse{AccountName: "JOHN DOE", AccountNumber: "0123456789", BankCode: "058"}, func(data []byte) (interface{}, error) {
+			return NewNameEnquiryResponseFromJSON(data)
+		})
+	})
+	t.Run("TransferResponse", func(t *testing.T) {
+		roundTrip(t, &TransferResponse{TransactionRef: "0000000000001070108", Status: "SUCCESSFUL", Fee: 52.5}, func(data []byte) (interface{}, error) {
+			return NewTransferResponseFromJSON(data)
+		})
+	})
+	t.Run("AirtimeResponse", func(t *testing.T) {
+		roundTrip(t, &AirtimeResponse{TransactionRef: "0000000000001070108", Status: "SUCCESSFUL", Amount: 100}, func(data []byte) (interface{}, error) {
+			return NewAirtimeResponseFromJSON(data)
+		})
+	})
+	t.Run("VirtualAccountResponse", func(t *testing.T) {
+		roundTrip(t, &VirtualAccountResponse{AccountNumber: "9900000001"}, func(data []byte) (interface{}, error) {
+			return NewVirtualAccountResponseFromJSON(data)
+		})
+	})
+	t.Run("CreateAgentResponse", func(t *testing.T) {
+		roundTrip(t, &CreateAgentResponse{AgentID: "AG-1", Status: "ACTIVE"}, func(data []byte) (interface{}, error) {
+			return NewCreateAgentResponseFromJSON(data)
+		})
+	})
+	t.Run("RegisterUserResponse", func(t *testing.T) {
+		roundTrip(t, &RegisterUserResponse{WalletID: "W-1", Phone: "08031234567", Status: "ACTIVE"}, func(data []byte) (interface{}, error) {
+			return NewRegisterUserResponseFromJSON(data)
+		})
+	})
+	t.Run("TransactionStatusResponse", func(t *testing.T) {
+		roundTrip(t, &TransactionStatusResponse{TransactionRef: "ref", Amount: 250, ResponseCode: "00", CompletionDate: 1626875711000}, func(data []byte) (interface{}, error) {
+			return NewTransactionStatusResponseFromJSON(data)
+		})
+	})
+	t.Run("Bank", func(t *testing.T) {
+		roundTrip(t, &Bank{Code: "058", Name: "GTBank"}, func(data []byte) (interface{}, error) {
+			return NewBankFromJSON(data)
+		})
+	})
+}
+
+func TestWalletTransactionFormattedDateZone(t *testing.T) {
+
+	data := []byte(`[{"date": 1622307120000, "reciept": {"date": 1622307120000}}]`)
+
+	transactions, err := NewWalletTransactions(data)
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, "2021-05-29T16:52:00Z", transactions[0].FormattedDate)
+	assert.Equal(t, "2021-05-29T16:52:00Z", transactions[0].Reciept.FormattedDate)
+
+	lagos := time.FixedZone("WAT", 60*60)
+	transactions, err = NewWalletTransactionsIn(data, lagos)
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, "2021-05-29T17:52:00+01:00", transactions[0].FormattedDate)
+	assert.Equal(t, "2021-05-29T17:52:00+01:00", transactions[0].Reciept.FormattedDate)
+}
+
+func TestNewWalletTransactionsDetectsSchoolable(t *testing.T) {
+
+	transactions, err := NewWalletTransactions([]byte(`[
+		{"tranId": 4, "narration": "School fees", "thirdParty": {"provider": "Schoolable", "reference": "SCH-1"}},
+		{"tranId": 3, "narration": "School fees", "thirdParty": "SCHOOLABLE"},
+		{"tranId": 2, "description": "Schoolable payment for Greenfield Academy", "thirdParty": null},
+		{"tranId": 1, "description": "Transfer to 08031234567", "thirdParty": {"provider": "OTHER"}},
+		{"tranId": 0, "description": "Cash deposit"}
+	]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assert.True(t, transactions[0].IsSchoolable())
+	assert.True(t, transactions[1].IsSchoolable())
+	assert.True(t, transactions[2].IsSchoolable())
+	assert.False(t, transactions[3].IsSchoolable())
+	assert.Equal(t, "OTHER", transactions[3].Provider)
+	assert.False(t, transactions[4].IsSchoolable())
+	assert.Empty(t, transactions[4].Provider)
+}
+
+func TestThirdPartyInfo(t *testing.T) {
+
+	transactions, err := NewWalletTransactions([]byte(`[
+		{"tranId": 2, "narration": "School fees", "thirdParty": {"provider": "Schoolable", "reference": "SCH-1", "status": "SETTLED"}},
+		{"tranId": 1, "narration": "School fees", "thirdParty": "SCHOOLABLE"},
+		{"tranId": 0, "description": "Cash deposit", "thirdParty": null}
+	]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assert.Equal(t, &ThirdPartyInfo{Provider: "Schoolable", Reference: "SCH-1", Status: "SETTLED"}, transactions[0].ThirdParty)
+	assert.Equal(t, &ThirdPartyInfo{Provider: "SCHOOLABLE"}, transactions[1].ThirdParty)
+	assert.Nil(t, transactions[2].ThirdParty)
+
+	data, err := transactions[0].Marshal()
+	if err != nil {
+		t.Fatalf("Did not expect marshalling to fail: %v", err)
+	}
+	decoded, err := NewWalletTransactionFromJSON(data)
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, transactions[0].ThirdParty, decoded.ThirdParty)
+
+	ussd, err := NewUssdTransactionResponse([]byte(`{
+		"transactionRef": "1234",
+		"status": "SUCCESSFUL",
+		"thirdParty": {"provider": "Schoolable", "reference": "SCH-2", "status": "PENDING"}
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, &ThirdPartyInfo{Provider: "Schoolable", Reference: "SCH-2", Status: "PENDING"}, ussd.ThirdParty)
+}
+
+func TestDetectPosTerminalAndTransactionID(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		longDescription string
+		narration       string
+		terminalID      string
+		transactionID   string
+	}{
+		{
+			name:            "terminal and cashback",
+			longDescription: "Money deposited using terminal 2058LS41",
+			narration:       "AGENT POS CASHBACK 2058LS41 000001",
+			terminalID:      "2058LS41",
+			transactionID:   "000001",
+		},
+		{
+			name:            "trailing whitespace and location",
+			longDescription: "Money deposited using terminal 2058LS41   at Ikeja branch ",
+			narration:       "  agent pos cashback 2058LS41 000000123456 school fees  ",
+			terminalID:      "2058LS41",
+			transactionID:   "000000123456",
+		},
+		{
+			name:            "numeric terminal",
+			longDescription: "POS withdrawal",
+			narration:       "AGENT POS CASHBACK 20581234 004567",
+			terminalID:      "20581234",
+			transactionID:   "004567",
+		},
+		{
+			name:          "transaction id only",
+			narration:     "AGENT POS CASHBACK 000789",
+			transactionID: "000789",
+		},
+		{
+			name:            "ussd terminal",
+			longDescription: "Money deposited using terminal USSD0000111111 ",
+			narration:       "USSD/0000111111/0000000000011111",
+			terminalID:      "USSD0000111111",
+		},
+		{
+			name:            "no terminal token",
+			longDescription: "Money deposited using terminal ",
+			narration:       "AGENT POS CASHBACK",
+		},
+		{
+			name:      "terminal without transaction id",
+			narration: "AGENT POS CASHBACK 2058LS41",
+		},
+		{
+			name:            "unrelated transfer",
+			longDescription: "Transfer to 0123456789",
+			narration:       "Transfer from John Doe for cashback promo 123456",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := WalletTransaction{LongDescription: tt.longDescription, Narration: tt.narration}
+			transaction.detectPosTerminalAndTransactionID()
+			assert.Equal(t, tt.terminalID, transaction.PosTerminalID)
+			assert.Equal(t, tt.transactionID, transaction.PosTransactionID)
+		})
+	}
+}
+
+func TestResponsesParseFees(t *testing.T) {
+
+	transfer, err := NewTransferResponse([]byte(`{
+		"transactionRef": "0000000000001070108",
+		"status": "SUCCESSFUL",
+		"amount": 10000,
+		"fee": 52.5
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, 52.5, transfer.Fee)
+	assert.Equal(t, 9947.5, transfer.NetAmount())
+
+	airtime, err := NewAirtimeResponse([]byte(`{
+		"transactionRef": "0000000000001070109",
+		"status": "SUCCESSFUL",
+		"amount": 500,
+		"fee": 2.5
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, 2.5, airtime.Fee)
+	assert.Equal(t, 497.5, airtime.NetAmount())
+
+	airtime, err = NewAirtimeResponse([]byte(`{"transactionRef": "0000000000001070110", "amount": 500}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Zero(t, airtime.Fee)
+	assert.Equal(t, float64(500), airtime.NetAmount())
+
+	ussd, err := NewUssdTransactionResponse([]byte(`{
+		"merchantRef": "0000000000011715",
+		"amount": 1000,
+		"fee": 15,
+		"status": "SUCCESSFUL"
+	}`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, float64(15), ussd.Fee)
+
+	transactions, err := NewWalletTransactions([]byte(`[
+		{
+			"debit": true,
+			"tranId": 32101362,
+			"tranType": "200.22.0000",
+			"description": "Transfer to 0123456789",
+			"date": 1622290322000,
+			"amount": 4500.00,
+			"fee": 52.5
+		}
+	]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+	assert.Equal(t, 52.5, transactions[0].Fee)
+}
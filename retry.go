@@ -0,0 +1,157 @@
+package readycash
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//RetryPolicy configures how failed requests are retried.
+//GET requests and read-only POST operations are retried on network errors,
+//5xx and 429 responses, money moving operations carrying a reference are
+//only retried when RetryIdempotentPosts is set. A 429 is retried after the
+//wait its Retry-After header asks for, or given up on when that is longer
+//than MaxDelay
+type RetryPolicy struct {
+	MaxRetries           int
+	BaseDelay            time.Duration
+	MaxDelay             time.Duration
+	RetryIdempotentPosts bool
+}
+
+//backoff doubles BaseDelay for every attempt up to MaxDelay, no MaxDelay
+//means no cap. The limit is checked before shifting so a large attempt
+//cannot overflow into a short delay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	limit := p.MaxDelay
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	if attempt >= 63 || p.BaseDelay > limit>>uint(attempt) {
+		return limit
+	}
+	return p.BaseDelay << uint(attempt)
+}
+
+type retryMode int
+
+const (
+	retryNever retryMode = iota
+	retrySafe
+	retryIdempotent
+)
+
+type retryModeKey struct{}
+
+func withRetryMode(ctx context.Context, mode retryMode) context.Context {
+	return context.WithValue(ctx, retryModeKey{}, mode)
+}
+
+//SetRetryPolicy changes how the client retries transient failures
+func (r *Client) SetRetryPolicy(p RetryPolicy) {
+	r.retryPolicy = p
+}
+
+func (r *Client) isRetryable(req *http.Request) bool {
+	if r.retryPolicy.MaxRetries <= 0 {
+		return false
+	}
+
+	if req.Method == http.MethodGet {
+		return true
+	}
+
+	mode, _ := req.Context().Value(retryModeKey{}).(retryMode)
+	switch mode {
+	case retrySafe:
+		return true
+	case retryIdempotent:
+		return r.retryPolicy.RetryIdempotentPosts
+	}
+	return false
+}
+
+func (r *Client) shouldRetry(req *http.Request, statusCode int, err error) bool {
+	if req.Context().Err() != nil || errors.Is(err, ErrResponseTooLarge) {
+		return false
+	}
+	return err != nil || statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests
+}
+
+//retryDelay is how long to wait before the next attempt, a 429 waits as
+//long as its Retry-After header asks. It reports false when that is longer
+//than the policy allows
+func (r *Client) retryDelay(attempt int, statusCode int, header http.Header) (time.Duration, bool) {
+	if statusCode != http.StatusTooManyRequests {
+		return r.retryPolicy.backoff(attempt), true
+	}
+
+	delay, ok := parseRetryAfter(header.Get("Retry-After"), r.clock.Now())
+	if !ok {
+		return r.retryPolicy.backoff(attempt), true
+	}
+	if r.retryPolicy.MaxDelay > 0 && delay > r.retryPolicy.MaxDelay {
+		return 0, false
+	}
+	return delay, true
+}
+
+//parseRetryAfter reads a Retry-After header given either in seconds or as
+//an http date, a date in the past means no wait
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+func (r *Client) waitForRetry(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retryReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retryReq.Body = body
+	}
+	return retryReq, nil
+}
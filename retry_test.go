@@ -0,0 +1,242 @@
+package readycash
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlakyServer(path string, failures int32, successBody string) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == path {
+			if atomic.AddInt32(&hits, 1) <= failures {
+				rw.WriteHeader(http.StatusInternalServerError)
+				rw.Write([]byte(`{"Status": 500, "Code": 500, "Message": "temporarily unavailable"}`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(successBody))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	return server, &hits
+}
+
+func TestRetryPolicyRetriesGetRequests(t *testing.T) {
+
+	server, hits := newFlakyServer(baseBalanceUrl, 2, `{"income": "5000","main": "1000"}`)
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Millisecond,
+	})
+
+	resp, err := apiClient.BalanceEnquiry()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+	assert.Equal(t, float64(1000), resp.Main)
+}
+
+func TestRetryPolicySkipsPostsUnlessOptedIn(t *testing.T) {
+
+	transferRequest := BankTransferRequest{
+		Amount:        2500,
+		AccountNumber: "0123456789",
+		BankCode:      "058",
+		Reference:     "user-defined-ref",
+	}
+	successBody := `{"transactionRef": "0000000000001070108", "status": "SUCCESSFUL"}`
+
+	server, hits := newFlakyServer(baseBankFundsTransferUrl, 2, successBody)
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+	})
+
+	_, err = apiClient.BankFundsTransfer(transferRequest)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+
+	atomic.StoreInt32(hits, 0)
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries:           3,
+		BaseDelay:            time.Millisecond,
+		RetryIdempotentPosts: true,
+	})
+
+	resp, err := apiClient.BankFundsTransfer(transferRequest)
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+	assert.Equal(t, "SUCCESSFUL", resp.Status)
+}
+
+func TestRetryPolicyHonorsContextCancellation(t *testing.T) {
+
+	server, hits := newFlakyServer(baseBalanceUrl, 5, `{"income": "5000","main": "1000"}`)
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = apiClient.BalanceEnquiryContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	assert.Equal(t, 10*time.Millisecond, policy.backoff(0))
+	assert.Equal(t, 20*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 40*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(40))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(64))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(1000))
+
+	noBase := RetryPolicy{MaxDelay: 50 * time.Millisecond}
+	assert.Equal(t, time.Duration(0), noBase.backoff(0))
+	assert.Equal(t, time.Duration(0), noBase.backoff(5))
+
+	uncapped := RetryPolicy{BaseDelay: time.Second}
+	assert.Equal(t, 8*time.Second, uncapped.backoff(3))
+	for _, attempt := range []int{34, 62, 63, 100} {
+		assert.Equal(t, time.Duration(math.MaxInt64), uncapped.backoff(attempt), "attempt %d", attempt)
+	}
+}
+
+func TestRetryAfterOnTooManyRequests(t *testing.T) {
+
+	var hits int32
+	var retryAfter atomic.Value
+	retryAfter.Store("1")
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseBalanceUrl {
+			if atomic.AddInt32(&hits, 1)%2 == 1 {
+				rw.Header().Add("Retry-After", retryAfter.Load().(string))
+				rw.WriteHeader(http.StatusTooManyRequests)
+				rw.Write([]byte(`{"Status": 429, "Code": 429, "Message": "slow down"}`))
+				return
+			}
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"income": "5000","main": "1000"}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries: 1,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Second,
+	})
+
+	start := time.Now()
+	resp, err := apiClient.BalanceEnquiry()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Second))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	assert.Equal(t, float64(1000), resp.Main)
+
+	retryAfter.Store("60")
+	_, err = apiClient.BalanceEnquiry()
+	assert.ErrorIs(t, err, &ErrorResponse{HTTPStatus: http.StatusTooManyRequests})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits), "a wait longer than MaxDelay should not be retried")
+
+	atomic.StoreInt32(&hits, 0)
+	retryAfter.Store("2")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = apiClient.BalanceEnquiryContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+
+	now := time.Date(2021, 5, 29, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{"1", time.Second, true},
+		{" 120 ", 2 * time.Minute, true},
+		{"Sat, 29 May 2021 12:00:30 GMT", 30 * time.Second, true},
+		{"Sat, 29 May 2021 11:59:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tc := range testCases {
+		delay, ok := parseRetryAfter(tc.value, now)
+		assert.Equal(t, tc.ok, ok, "value %q", tc.value)
+		assert.Equal(t, tc.delay, delay, "value %q", tc.value)
+	}
+}
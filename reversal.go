@@ -0,0 +1,161 @@
+package readycash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+//Sentinel errors for a reversal the api refuses, they are matched with
+//errors.Is alongside the *ErrorResponse the api returned
+var (
+	ErrAlreadyReversed = errors.New("transaction has already been reversed")
+	ErrNotReversible   = errors.New("transaction cannot be reversed")
+)
+
+//reversalErrorCodes maps the codes the reversal endpoint refuses a
+//reversal with to sentinels, they only have this meaning for reversals so
+//they are matched here rather than through apiErrorCodes
+var reversalErrorCodes = map[int]error{
+	12: ErrNotReversible,
+	57: ErrNotReversible,
+	94: ErrAlreadyReversed,
+}
+
+//reversalError is an *ErrorResponse from the reversal endpoint that
+//matches the sentinel its code maps to instead of the one in apiErrorCodes,
+//so an already reversed transaction is not also a duplicate reference.
+//errors.As still finds the *ErrorResponse
+type reversalError struct {
+	*ErrorResponse
+	sentinel error
+}
+
+func (e *reversalError) Is(target error) bool {
+	if t, ok := target.(*ErrorResponse); ok {
+		return e.ErrorResponse.Is(t)
+	}
+	return target == e.sentinel
+}
+
+func (e *reversalError) As(target interface{}) bool {
+	if t, ok := target.(**ErrorResponse); ok {
+		*t = e.ErrorResponse
+		return true
+	}
+	return false
+}
+
+//toReversalError adds the reversal sentinel to err when it is an
+//*ErrorResponse with one of the reversalErrorCodes
+func toReversalError(err error) error {
+	var errResponse *ErrorResponse
+	if !errors.As(err, &errResponse) {
+		return err
+	}
+	sentinel, ok := reversalErrorCodes[errResponse.Code]
+	if !ok {
+		return err
+	}
+	return &reversalError{ErrorResponse: errResponse, sentinel: sentinel}
+}
+
+//ReverseTransaction asks the api to reverse the transaction with
+//transactionRef and returns its new status. A refused reversal matches
+//ErrAlreadyReversed or ErrNotReversible with errors.Is
+func (r *Client) ReverseTransaction(transactionRef string, reason string) (*TransactionStatusResponse, error) {
+	return r.ReverseTransactionContext(context.Background(), transactionRef, reason)
+}
+
+//ReverseTransactionContext is like ReverseTransaction but uses ctx for the request
+func (r *Client) ReverseTransactionContext(ctx context.Context, transactionRef string, reason string) (*TransactionStatusResponse, error) {
+	if r.shouldAudit(ctx) {
+		res, err := r.ReverseTransactionContext(withAudited(ctx), transactionRef, reason)
+		record := AuditRecord{Operation: "ReverseTransaction", Reference: transactionRef, TransactionRef: transactionRef, Err: err}
+		if res != nil {
+			record.Amount, record.Status = res.Amount, res.Status
+		}
+		r.audit(record)
+		return res, err
+	}
+
+	ctx = withOperation(ctx, "ReverseTransaction")
+	ctx = ensureIdempotencyKey(ctx)
+	reqLogger := r.getRequestLogger(map[string]interface{}{
+		"method": "ReverseTransaction",
+		"ref":    transactionRef,
+		"reason": reason,
+	})
+
+	if transactionRef == "" {
+		reqLogger.Error("transaction ref is required")
+		return nil, fmt.Errorf("%w: transactionRef", ErrMissingRequiredField)
+	}
+
+	if r.dryRun {
+		r.logDryRun(reqLogger, map[string]interface{}{"ref": transactionRef, "reason": reason})
+		return dryRunReversalResponse(transactionRef), nil
+	}
+
+	if err := r.ensureUserIsAuthenticated(ctx); err != nil {
+		reqLogger.WithError(err).Error("could not ensure user is authenticated")
+		return nil, err
+	}
+
+	payloadReader, err := r.fromMapToReader(map[string]interface{}{
+		"ref":    transactionRef,
+		"reason": reason,
+		"pin":    r.session().encodedPin,
+	})
+	if err != nil {
+		reqLogger.WithError(err).Error("could not convert req payload to reader")
+		return nil, err
+	}
+
+	reverseUrl := r.generateUrl(reverseTransactionUrl)
+	request, err := r.newPostRequest(withRetryMode(ctx, retryIdempotent), reverseUrl, payloadReader)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error creating post request to reverse transaction")
+		return nil, err
+	}
+
+	statusCode, data, err := r.doRequest(request)
+	if err != nil {
+		reqLogger.WithError(err).Error("encountered error doing post request to reverse transaction")
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		retryCtx, ok := r.retryAfterForbidden(ctx)
+		if !ok {
+			reqLogger.Error("request was still forbidden after logging in again")
+			return nil, ErrForbiddenAfterRetry
+		}
+		return r.ReverseTransactionContext(retryCtx, transactionRef, reason)
+	}
+
+	if len(data) == 0 {
+		reqLogger.Error("did not return any response content")
+		return nil, ErrEmptyResponse
+	}
+
+	reqLogger.WithField("response", string(data)).Debug("reverse transaction response")
+
+	if !r.successCode(statusCode) {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("status code received is not success")
+		return nil, toReversalError(r.toErrorResponse(statusCode, data))
+	}
+
+	res, err := NewTransactionStatusResponse(data)
+	if err != nil {
+		reqLogger.WithField("status_code", statusCode).
+			WithField("data", string(data)).
+			Error("error regenerating transaction status model from response")
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,95 @@
+package readycash
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseTransaction(t *testing.T) {
+
+	testResults := struct {
+		payload map[string]string
+		status  int
+		body    string
+	}{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == reverseTransactionUrl && req.Method == http.MethodPost {
+			json.NewDecoder(req.Body).Decode(&testResults.payload)
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(testResults.status)
+			rw.Write([]byte(testResults.body))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	_, err = apiClient.ReverseTransaction("", "customer request")
+	assert.ErrorIs(t, err, ErrMissingRequiredField)
+
+	testResults.status = http.StatusOK
+	testResults.body = `{
+		"transactionRef": "RC-0001",
+		"tranType": "420.00.010.0000",
+		"amount": 992,
+		"responseCode": "00",
+		"status": "SUCCESSFUL"
+	}`
+	resp, err := apiClient.ReverseTransaction("RC-0001", "customer request")
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	expectedPin, _ := DesEncrypt([]byte("1234"), []byte("1234"))
+	assert.Equal(t, map[string]string{"ref": "RC-0001", "reason": "customer request", "pin": expectedPin}, testResults.payload)
+	assert.Equal(t, "RC-0001", resp.TransactionRef)
+	assert.Equal(t, reversedTransactionType, resp.TranType)
+	assert.Equal(t, float64(992), resp.Amount)
+
+	testResults.status = http.StatusBadRequest
+	testResults.body = `{"Status": 400, "Code": 57, "Message": "transaction not permitted"}`
+	_, err = apiClient.ReverseTransaction("RC-0002", "customer request")
+	assert.ErrorIs(t, err, ErrNotReversible)
+	assert.NotErrorIs(t, err, ErrAlreadyReversed)
+	var errorResponse *ErrorResponse
+	if assert.ErrorAs(t, err, &errorResponse) {
+		assert.Equal(t, 57, errorResponse.Code)
+		assert.Equal(t, http.StatusBadRequest, errorResponse.HTTPStatus)
+	}
+
+	testResults.body = `{"Status": 400, "Code": 94, "Message": "already reversed"}`
+	_, err = apiClient.ReverseTransaction("RC-0001", "customer request")
+	assert.ErrorIs(t, err, ErrAlreadyReversed)
+	assert.NotErrorIs(t, err, ErrNotReversible)
+	assert.False(t, errors.Is(err, ErrDuplicateReference))
+	assert.ErrorIs(t, err, &ErrorResponse{HTTPStatus: http.StatusBadRequest, Code: 94})
+	if assert.ErrorAs(t, err, &errorResponse) {
+		assert.Equal(t, 94, errorResponse.Code)
+	}
+
+	testResults.body = `{"Status": 400, "Code": 51, "Message": "insufficient funds"}`
+	_, err = apiClient.ReverseTransaction("RC-0003", "customer request")
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	assert.NotErrorIs(t, err, ErrNotReversible)
+	assert.NotErrorIs(t, err, ErrAlreadyReversed)
+}
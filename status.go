@@ -0,0 +1,78 @@
+package readycash
+
+import (
+	"fmt"
+	"strings"
+)
+
+//TransactionStatus is the status string the api reports for a transaction
+type TransactionStatus string
+
+const (
+	StatusAwaitingCustomer TransactionStatus = "AWAITING CUSTOMER"
+	StatusPending          TransactionStatus = "PENDING"
+	StatusSuccessful       TransactionStatus = "SUCCESSFUL"
+	StatusFailed           TransactionStatus = "FAILED"
+	StatusExpired          TransactionStatus = "EXPIRED"
+)
+
+const (
+	ResponseCodeApproved   = "00"
+	ResponseCodeInProgress = "09"
+)
+
+func normalizeStatus(status string) TransactionStatus {
+	return TransactionStatus(strings.ToUpper(strings.TrimSpace(status)))
+}
+
+//IsSuccessful reports whether the payment completed, going by either the status or the response code
+func (r *UssdTransactionResponse) IsSuccessful() bool {
+	return normalizeStatus(r.Status) == StatusSuccessful || r.ResponseCode == ResponseCodeApproved
+}
+
+//IsPending reports whether the payment is still waiting on the customer
+func (r *UssdTransactionResponse) IsPending() bool {
+	if r.IsSuccessful() {
+		return false
+	}
+
+	switch normalizeStatus(r.Status) {
+	case StatusAwaitingCustomer, StatusPending:
+		return true
+	case StatusFailed, StatusExpired:
+		return false
+	}
+	return r.ResponseCode == ResponseCodeInProgress
+}
+
+//responseCodeMeanings describes the iso 8583 style response codes the api returns
+var responseCodeMeanings = map[string]string{
+	ResponseCodeApproved:   "Approved",
+	"01":                   "Refer to card issuer",
+	"03":                   "Invalid merchant",
+	"05":                   "Do not honour",
+	"06":                   "Error",
+	ResponseCodeInProgress: "Request in progress",
+	"12":                   "Invalid transaction",
+	"13":                   "Invalid amount",
+	"14":                   "Invalid account",
+	"25":                   "Unable to locate record",
+	"51":                   "Insufficient funds",
+	"54":                   "Expired card",
+	"55":                   "Incorrect pin",
+	"57":                   "Transaction not permitted",
+	"61":                   "Exceeds withdrawal limit",
+	"91":                   "Issuer or switch inoperative",
+	"94":                   "Duplicate transaction",
+	"96":                   "System malfunction",
+}
+
+//ResponseCodeMeaning returns a readable description of ResponseCode, or
+//"Unknown (<code>)" for a code it does not know
+func (r *UssdTransactionResponse) ResponseCodeMeaning() string {
+	code := strings.TrimSpace(r.ResponseCode)
+	if meaning, ok := responseCodeMeanings[code]; ok {
+		return meaning
+	}
+	return fmt.Sprintf("Unknown (%s)", code)
+}
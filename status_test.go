@@ -0,0 +1,54 @@
+package readycash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUssdTransactionStatus(t *testing.T) {
+
+	testCases := []struct {
+		status       string
+		responseCode string
+		successful   bool
+		pending      bool
+	}{
+		{"SUCCESSFUL", "00", true, false},
+		{"successful", "", true, false},
+		{"", "00", true, false},
+		{"AWAITING CUSTOMER", "09", false, true},
+		{"PENDING", "", false, true},
+		{"", "09", false, true},
+		{"FAILED", "09", false, false},
+		{"EXPIRED", "", false, false},
+		{"", "", false, false},
+	}
+
+	for _, tc := range testCases {
+		resp := UssdTransactionResponse{Status: tc.status, ResponseCode: tc.responseCode}
+		assert.Equal(t, tc.successful, resp.IsSuccessful(), "status %q code %q", tc.status, tc.responseCode)
+		assert.Equal(t, tc.pending, resp.IsPending(), "status %q code %q", tc.status, tc.responseCode)
+	}
+}
+
+func TestUssdResponseCodeMeaning(t *testing.T) {
+
+	testCases := []struct {
+		responseCode string
+		meaning      string
+	}{
+		{"00", "Approved"},
+		{"09", "Request in progress"},
+		{" 51 ", "Insufficient funds"},
+		{"55", "Incorrect pin"},
+		{"91", "Issuer or switch inoperative"},
+		{"Z9", "Unknown (Z9)"},
+		{"", "Unknown ()"},
+	}
+
+	for _, tc := range testCases {
+		resp := UssdTransactionResponse{ResponseCode: tc.responseCode}
+		assert.Equal(t, tc.meaning, resp.ResponseCodeMeaning(), "code %q", tc.responseCode)
+	}
+}
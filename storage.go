@@ -0,0 +1,124 @@
+package readycash
+
+import (
+	"context"
+	"time"
+)
+
+//BasicStorage is the part of Storage that sets and reads values
+type BasicStorage interface {
+	SetStringFor(key, val string, exp time.Duration) error
+	SetIntFor(key string, val int64, exp time.Duration) error
+	GetString(key string) (string, error)
+	GetInt(key string) (int64, error)
+}
+
+//deletedValueTTL is how long the empty value written by storageAdapter's
+//Delete lives before the backing store expires it
+const deletedValueTTL = time.Millisecond
+
+//NewStorageAdapter makes a Storage out of a store that cannot delete keys.
+//Delete overwrites the key with an empty value that expires almost
+//straight away, the client already treats empty values as missing
+func NewStorageAdapter(s BasicStorage) Storage {
+	if storage, ok := s.(Storage); ok {
+		return storage
+	}
+	return storageAdapter{s}
+}
+
+type storageAdapter struct {
+	BasicStorage
+}
+
+func (a storageAdapter) Delete(key string) error {
+	return a.SetStringFor(key, "", deletedValueTTL)
+}
+
+//ContextStorage is a Storage whose calls honour cancellation and deadlines.
+//The client uses it when the Storage it is given implements it and wraps
+//other stores with NewContextStorageAdapter
+type ContextStorage interface {
+	SetStringForContext(ctx context.Context, key, val string, exp time.Duration) error
+	SetIntForContext(ctx context.Context, key string, val int64, exp time.Duration) error
+	GetStringContext(ctx context.Context, key string) (string, error)
+	GetIntContext(ctx context.Context, key string) (int64, error)
+	DeleteContext(ctx context.Context, key string) error
+}
+
+//NewContextStorageAdapter makes a ContextStorage out of a Storage. Calls
+//on stores that do not take a context run in their own goroutine and are
+//abandoned with ctx's error once ctx is done
+func NewContextStorageAdapter(s Storage) ContextStorage {
+	if storage, ok := s.(ContextStorage); ok {
+		return storage
+	}
+	return contextStorageAdapter{s}
+}
+
+type contextStorageAdapter struct {
+	storage Storage
+}
+
+func (a contextStorageAdapter) SetStringForContext(ctx context.Context, key, val string, exp time.Duration) error {
+	return runWithContext(ctx, func() error {
+		return a.storage.SetStringFor(key, val, exp)
+	})
+}
+
+func (a contextStorageAdapter) SetIntForContext(ctx context.Context, key string, val int64, exp time.Duration) error {
+	return runWithContext(ctx, func() error {
+		return a.storage.SetIntFor(key, val, exp)
+	})
+}
+
+func (a contextStorageAdapter) GetStringContext(ctx context.Context, key string) (string, error) {
+	var val string
+	if err := runWithContext(ctx, func() (err error) {
+		val, err = a.storage.GetString(key)
+		return err
+	}); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func (a contextStorageAdapter) GetIntContext(ctx context.Context, key string) (int64, error) {
+	var val int64
+	if err := runWithContext(ctx, func() (err error) {
+		val, err = a.storage.GetInt(key)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return val, nil
+}
+
+func (a contextStorageAdapter) DeleteContext(ctx context.Context, key string) error {
+	return runWithContext(ctx, func() error {
+		return a.storage.Delete(key)
+	})
+}
+
+//runWithContext runs fn and waits for it until ctx is done. Values fn
+//writes must only be read when runWithContext returns nil
+func runWithContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
@@ -0,0 +1,108 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockStoreDelete(t *testing.T) {
+
+	store := NewMockStore()
+	assert.NoError(t, store.SetStringFor("auth-token", "Bearer Token", time.Minute))
+	assert.NoError(t, store.SetIntFor("auth-expiration", 1622307120, time.Minute))
+
+	assert.NoError(t, store.Delete("auth-token"))
+	assert.NoError(t, store.Delete("auth-expiration"))
+	assert.NoError(t, store.Delete("never-set"))
+
+	_, err := store.GetString("auth-token")
+	assert.Error(t, err)
+	_, err = store.GetInt("auth-expiration")
+	assert.Error(t, err)
+}
+
+func TestStorageAdapter(t *testing.T) {
+
+	store := NewMockStore()
+	assert.Same(t, store, NewStorageAdapter(store))
+
+	adapted := NewStorageAdapter(struct{ BasicStorage }{store})
+	assert.NoError(t, adapted.SetStringFor("auth-token", "Bearer Token", time.Minute))
+	assert.NoError(t, adapted.SetIntFor("auth-expiration", 1622307120, time.Minute))
+
+	assert.NoError(t, adapted.Delete("auth-token"))
+	assert.NoError(t, adapted.Delete("auth-expiration"))
+
+	token, _ := adapted.GetString("auth-token")
+	assert.Empty(t, token)
+
+	assert.Eventually(t, func() bool {
+		_, err := adapted.GetString("auth-token")
+		return err != nil
+	}, time.Second, deletedValueTTL)
+	_, err := adapted.GetInt("auth-expiration")
+	assert.Error(t, err)
+}
+
+//blockingStore is a context free store whose reads hang until release is closed
+type blockingStore struct {
+	*mockStore
+	release chan struct{}
+}
+
+func (s blockingStore) GetString(key string) (string, error) {
+	<-s.release
+	return s.mockStore.GetString(key)
+}
+
+func TestContextStorageAdapterHonoursCancellation(t *testing.T) {
+
+	store := blockingStore{mockStore: NewMockStore(), release: make(chan struct{})}
+	defer close(store.release)
+
+	adapted := NewContextStorageAdapter(store)
+	assert.NoError(t, adapted.SetStringForContext(context.Background(), "auth-token", "Bearer Token", time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := adapted.GetStringContext(ctx, "auth-token")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	_, err = adapted.GetIntContext(ctx, "auth-expiration")
+	assert.ErrorIs(t, err, context.Canceled, "a done ctx should not reach the store")
+}
+
+func TestStorageCancellationStopsLogin(t *testing.T) {
+
+	store := blockingStore{mockStore: NewMockStore(), release: make(chan struct{})}
+	defer close(store.release)
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Errorf("Did not expect a request to %s", req.URL.String())
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, store, server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = apiClient.BalanceEnquiryContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,35 @@
+package readycash
+
+import "math"
+
+//TransactionSummary holds aggregate figures over a set of wallet transactions
+type TransactionSummary struct {
+	Count       int
+	TotalDebit  float64
+	TotalCredit float64
+	//Net is TotalCredit less TotalDebit, so money out of the wallet makes it negative
+	Net float64
+}
+
+//SummarizeTransactions adds up the debits and credits in txns. Totals are
+//rounded to kobo so float errors do not build up over long lists
+func SummarizeTransactions(txns []WalletTransaction) TransactionSummary {
+	var summary TransactionSummary
+	for _, txn := range txns {
+		summary.Count++
+		if txn.Debit {
+			summary.TotalDebit += txn.Amount
+		} else {
+			summary.TotalCredit += txn.Amount
+		}
+	}
+
+	summary.TotalDebit = roundToKobo(summary.TotalDebit)
+	summary.TotalCredit = roundToKobo(summary.TotalCredit)
+	summary.Net = roundToKobo(summary.TotalCredit - summary.TotalDebit)
+	return summary
+}
+
+func roundToKobo(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
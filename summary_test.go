@@ -0,0 +1,34 @@
+package readycash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeTransactions(t *testing.T) {
+
+	transactions, err := NewWalletTransactions([]byte(`[
+		{"tranId": 5, "debit": true, "amount": 2500.10},
+		{"tranId": 4, "debit": false, "amount": 4500.00},
+		{"tranId": 3, "debit": true, "amount": 0.20},
+		{"tranId": 2, "debit": false, "amount": 0.10},
+		{"tranId": 1, "debit": true, "amount": 1000}
+	]`))
+	if err != nil {
+		t.Fatalf("Did not expect parsing to fail: %v", err)
+	}
+
+	assert.Equal(t, TransactionSummary{
+		Count:       5,
+		TotalDebit:  3500.30,
+		TotalCredit: 4500.10,
+		Net:         999.80,
+	}, SummarizeTransactions(transactions))
+
+	assert.Equal(t, TransactionSummary{}, SummarizeTransactions(nil))
+
+	debitsOnly := SummarizeTransactions(transactions[:1])
+	assert.Equal(t, 1, debitsOnly.Count)
+	assert.Equal(t, -2500.10, debitsOnly.Net)
+}
@@ -0,0 +1,54 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+)
+
+//Tracer starts a span for each api operation, it mirrors the small part of
+//the OpenTelemetry tracer api the client needs so an adapter can be written
+//without the package depending on otel
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+//Span is a single traced operation started by a Tracer
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+//startSpan opens a span named after the operation of req and returns req
+//bound to the span context
+func (r *Client) startSpan(req *http.Request) (*http.Request, Span) {
+	operation := operationFromContext(req.Context())
+	ctx, span := r.tracer.Start(req.Context(), "readycash."+operation)
+	span.SetAttribute("readycash.operation", operation)
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("server.address", req.URL.Host)
+	return req.WithContext(ctx), span
+}
+
+//endSpan records the outcome of the request and closes span
+func endSpan(span Span, statusCode int, err error) {
+	if statusCode != 0 {
+		span.SetAttribute("http.status_code", statusCode)
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,91 @@
+package readycash
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSpanKey struct{}
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	errs       []error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *fakeSpan) RecordError(err error)                      { s.errs = append(s.errs, err) }
+func (s *fakeSpan) End()                                       { s.ended = true }
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &fakeSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, fakeSpanKey{}, span), span
+}
+
+func TestTracerWrapsOperations(t *testing.T) {
+
+	server, _ := newFlakyServer(baseBalanceUrl, 1, `{"income": "5000","main": "1000"}`)
+	defer server.Close()
+
+	var requestSpans []interface{}
+	httpClient := server.Client()
+	transport := httpClient.Transport
+	httpClient.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requestSpans = append(requestSpans, req.Context().Value(fakeSpanKey{}))
+		return transport.RoundTrip(req)
+	})
+
+	tracer := &fakeTracer{}
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), httpClient, WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	apiClient.SetRetryPolicy(RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Millisecond,
+	})
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+
+	serverURL, _ := url.Parse(server.URL)
+	if assert.Len(t, tracer.spans, 1) {
+		span := tracer.spans[0]
+		assert.Equal(t, "readycash.BalanceEnquiry", span.name)
+		assert.True(t, span.ended)
+		assert.Empty(t, span.errs)
+		assert.Equal(t, "BalanceEnquiry", span.attributes["readycash.operation"])
+		assert.Equal(t, http.StatusOK, span.attributes["http.status_code"])
+		assert.Equal(t, serverURL.Host, span.attributes["server.address"])
+		assert.Equal(t, []interface{}{nil, span, span}, requestSpans)
+	}
+
+	server.Close()
+	_, err = apiClient.FetchTransaction(nil)
+	assert.Error(t, err)
+	if assert.Len(t, tracer.spans, 2) {
+		span := tracer.spans[1]
+		assert.Equal(t, "readycash.FetchTransaction", span.name)
+		assert.True(t, span.ended)
+		assert.Len(t, span.errs, 1)
+		assert.NotContains(t, span.attributes, "http.status_code")
+	}
+}
@@ -0,0 +1,142 @@
+package readycash
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+//Connection pool defaults of the transport the client builds when it is
+//not given an http.Client. The api is a single host so all idle
+//connections may be kept for it
+const (
+	defaultMaxIdleConns    = 20
+	defaultMaxConnsPerHost = 50
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+//poolSettings are the pool options a pooled transport was built with
+type poolSettings struct {
+	maxIdleConns    int
+	maxConnsPerHost int
+	idleConnTimeout time.Duration
+}
+
+//pooledTransports holds one transport per poolSettings, so clients created
+//without an http.Client, like those of a Manager, share a connection pool
+//instead of each opening and leaking their own
+var pooledTransports = struct {
+	sync.Mutex
+	byPool map[poolSettings]http.RoundTripper
+}{byPool: map[poolSettings]http.RoundTripper{}}
+
+//WithTransport makes the client send requests through t. Proxy and TLS
+//settings from WithProxy and WithTLSConfig are only applied to t when it is
+//an *http.Transport
+func WithTransport(t http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = t
+	}
+}
+
+//WithProxy sends every request through the forward proxy at proxyURL
+func WithProxy(proxyURL *url.URL) Option {
+	return func(c *Client) {
+		c.proxyURL = proxyURL
+	}
+}
+
+//WithTLSConfig uses config for tls connections, e.g. to trust a private CA
+//or pin the server certificate
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+//WithMaxIdleConns sets how many idle connections the client keeps open,
+//it only applies when NewClient was not given an http.Client
+func WithMaxIdleConns(n int) Option {
+	return func(c *Client) {
+		c.maxIdleConns = n
+	}
+}
+
+//WithMaxConnsPerHost limits how many connections the client opens to the
+//api at once, zero means no limit. It only applies when NewClient was not
+//given an http.Client
+func WithMaxConnsPerHost(n int) Option {
+	return func(c *Client) {
+		c.maxConnsPerHost = n
+	}
+}
+
+//WithIdleConnTimeout sets how long an idle connection is kept open, it only
+//applies when NewClient was not given an http.Client
+func WithIdleConnTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.idleConnTimeout = timeout
+	}
+}
+
+//pooledTransport is the transport used when NewClient was not given an
+//http.Client, it is http.DefaultTransport with the pool options applied.
+//Clients with the same pool options get the same transport
+func (r *Client) pooledTransport() http.RoundTripper {
+	settings := poolSettings{
+		maxIdleConns:    r.maxIdleConns,
+		maxConnsPerHost: r.maxConnsPerHost,
+		idleConnTimeout: r.idleConnTimeout,
+	}
+
+	pooledTransports.Lock()
+	defer pooledTransports.Unlock()
+	if transport, ok := pooledTransports.byPool[settings]; ok {
+		return transport
+	}
+
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+	base = base.Clone()
+	base.MaxIdleConns = settings.maxIdleConns
+	base.MaxIdleConnsPerHost = settings.maxIdleConns
+	base.MaxConnsPerHost = settings.maxConnsPerHost
+	base.IdleConnTimeout = settings.idleConnTimeout
+	pooledTransports.byPool[settings] = base
+	return base
+}
+
+//configureTransport swaps the transport of the http client for one built
+//from the transport options. The injected client is copied, not modified,
+//and is left as is when no transport option was given
+func (r *Client) configureTransport() {
+	if r.transport == nil && r.proxyURL == nil && r.tlsConfig == nil && !r.ownsTransport {
+		return
+	}
+
+	transport := r.transport
+	if transport == nil && r.ownsTransport {
+		transport = r.pooledTransport()
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if base, ok := transport.(*http.Transport); ok && (r.proxyURL != nil || r.tlsConfig != nil) {
+		base = base.Clone()
+		if r.proxyURL != nil {
+			base.Proxy = http.ProxyURL(r.proxyURL)
+		}
+		if r.tlsConfig != nil {
+			base.TLSClientConfig = r.tlsConfig
+		}
+		transport = base
+	}
+
+	httpClient := *r.httpClient
+	httpClient.Transport = transport
+	r.httpClient = &httpClient
+}
@@ -0,0 +1,166 @@
+package readycash
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBalanceHandler(testResults *[]string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		*testResults = append(*testResults, req.URL.Host+req.URL.Path)
+		if req.URL.Path == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		rw.Header().Add("content-type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"income": "0","main": "1000"}`))
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+
+	var proxied []string
+	proxy := httptest.NewServer(newTestBalanceHandler(&proxied))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, "http://readycash.invalid", NewMockStore(), nil, WithProxy(proxyURL))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.BalanceEnquiry()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, float64(1000), resp.Main)
+	assert.Equal(t, []string{
+		"readycash.invalid" + baseLoginUrl,
+		"readycash.invalid" + baseBalanceUrl,
+	}, proxied)
+	assert.Nil(t, http.DefaultClient.Transport)
+}
+
+func TestWithTLSConfig(t *testing.T) {
+
+	var served []string
+	server := httptest.NewTLSServer(newTestBalanceHandler(&served))
+	defer server.Close()
+
+	testAccount := newTestAccount()
+	untrustingClient, err := NewClient(&testAccount, server.URL, NewMockStore(), &http.Client{})
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	_, err = untrustingClient.BalanceEnquiry()
+	assert.Error(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	injected := &http.Client{}
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), injected, WithTLSConfig(&tls.Config{RootCAs: roots}))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	resp, err := apiClient.BalanceEnquiry()
+	if err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, float64(1000), resp.Main)
+	assert.Nil(t, injected.Transport)
+}
+
+func TestWithTransport(t *testing.T) {
+
+	var served []string
+	server := httptest.NewServer(newTestBalanceHandler(&served))
+	defer server.Close()
+
+	var roundTrips int
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		roundTrips += 1
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	testAccount := newTestAccount()
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), nil, WithTransport(transport))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	if _, err := apiClient.BalanceEnquiry(); err != nil {
+		t.Fatalf("Did not expect call to fail: %v", err)
+	}
+	assert.Equal(t, 2, roundTrips)
+}
+
+func TestTransportPoolOptions(t *testing.T) {
+
+	testAccount := newTestAccount()
+
+	defaultClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	transport, ok := defaultClient.httpClient.Transport.(*http.Transport)
+	if assert.True(t, ok) {
+		assert.Equal(t, defaultMaxIdleConns, transport.MaxIdleConns)
+		assert.Equal(t, defaultMaxIdleConns, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, defaultMaxConnsPerHost, transport.MaxConnsPerHost)
+		assert.Equal(t, defaultIdleConnTimeout, transport.IdleConnTimeout)
+		assert.NotSame(t, http.DefaultTransport, transport)
+	}
+
+	tunedClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil,
+		WithMaxIdleConns(64), WithMaxConnsPerHost(128), WithIdleConnTimeout(30*time.Second))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	transport, ok = tunedClient.httpClient.Transport.(*http.Transport)
+	if assert.True(t, ok) {
+		assert.Equal(t, 64, transport.MaxIdleConns)
+		assert.Equal(t, 64, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 128, transport.MaxConnsPerHost)
+		assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	}
+
+	sameDefaults, err := NewClient(&testAccount, "http://localhost", NewMockStore(), nil)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.Same(t, defaultClient.httpClient.Transport, sameDefaults.httpClient.Transport)
+	assert.NotSame(t, defaultClient.httpClient.Transport, tunedClient.httpClient.Transport)
+
+	manager := NewManager("http://localhost", NewMockStore(), nil)
+	firstAccount, secondAccount := newTestAccount(), newTestAccount()
+	secondAccount.UserName = "another"
+	first, err := manager.Client(&firstAccount)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	second, err := manager.Client(&secondAccount)
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.Same(t, first.httpClient.Transport, second.httpClient.Transport)
+
+	injected := &http.Client{}
+	injectedClient, err := NewClient(&testAccount, "http://localhost", NewMockStore(), injected, WithMaxConnsPerHost(128))
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+	assert.Same(t, injected, injectedClient.httpClient)
+	assert.Nil(t, injectedClient.httpClient.Transport)
+	assert.Nil(t, http.DefaultClient.Transport)
+}
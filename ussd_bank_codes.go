@@ -1,29 +1,55 @@
 package readycash
 
+import (
+	"fmt"
+	"strings"
+)
+
 var (
 	BanksSupportedOnUssd = map[string]string{
-		"057": "",
-		"058": "",
-		"033": "",
-		"039": "",
-		"232": "",
-		"215": "",
-		"082": "",
-		"070": "",
-		"050": "",
-		"035": "",
-		"044": "",
-		"011": "",
-		"214": "",
+		"057": "Zenith Bank",
+		"058": "Guaranty Trust Bank",
+		"033": "United Bank for Africa",
+		"039": "Stanbic IBTC Bank",
+		"232": "Sterling Bank",
+		"215": "Unity Bank",
+		"082": "Keystone Bank",
+		"070": "Fidelity Bank",
+		"050": "Ecobank",
+		"035": "Wema Bank",
+		"044": "Access Bank",
+		"011": "First Bank",
+		"214": "First City Monument Bank",
 	}
 )
 
 
-func IsBankSupportedOnUSSD(bankCode string) bool {
-	for bankCode, _ := range BanksSupportedOnUssd {
-		if bankCode == bankCode {
-			return true
+//NormalizeBankCode left pads a bank code of one to three digits with zeros,
+//so "44" becomes "044". Anything else is reported as ErrInvalidBankCode
+func NormalizeBankCode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" || len(code) > 3 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidBankCode, code)
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidBankCode, code)
 		}
 	}
-	return false
+	return strings.Repeat("0", 3-len(code)) + code, nil
+}
+
+//BankNameForUSSDCode returns the name of the bank with the given ussd bank code
+func BankNameForUSSDCode(code string) (string, bool) {
+	normalized, err := NormalizeBankCode(code)
+	if err != nil {
+		return "", false
+	}
+	name, ok := BanksSupportedOnUssd[normalized]
+	return name, ok
+}
+
+func IsBankSupportedOnUSSD(bankCode string) bool {
+	_, ok := BankNameForUSSDCode(bankCode)
+	return ok
 }
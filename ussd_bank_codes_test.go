@@ -0,0 +1,108 @@
+package readycash
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBankSupportedOnUSSD(t *testing.T) {
+	assert.True(t, IsBankSupportedOnUSSD("044"))
+	assert.True(t, IsBankSupportedOnUSSD("058"))
+	assert.False(t, IsBankSupportedOnUSSD("999"))
+	assert.False(t, IsBankSupportedOnUSSD(""))
+}
+
+func TestBankNameForUSSDCode(t *testing.T) {
+	name, ok := BankNameForUSSDCode("057")
+	assert.True(t, ok)
+	assert.Equal(t, "Zenith Bank", name)
+
+	name, ok = BankNameForUSSDCode("058")
+	assert.True(t, ok)
+	assert.Equal(t, "Guaranty Trust Bank", name)
+
+	name, ok = BankNameForUSSDCode("999")
+	assert.False(t, ok)
+	assert.Empty(t, name)
+
+	for code, name := range BanksSupportedOnUssd {
+		assert.NotEmpty(t, name, "bank code %s has no name", code)
+	}
+}
+
+func TestNormalizeBankCode(t *testing.T) {
+	for input, expected := range map[string]string{
+		"44":   "044",
+		"044":  "044",
+		" 58 ": "058",
+		"7":    "007",
+		"214":  "214",
+	} {
+		code, err := NormalizeBankCode(input)
+		if err != nil {
+			t.Fatalf("Did not expect %q to fail: %v", input, err)
+		}
+		assert.Equal(t, expected, code, input)
+	}
+
+	for _, input := range []string{"", "  ", "0044", "4a", "-44", "04.4"} {
+		_, err := NormalizeBankCode(input)
+		assert.ErrorIs(t, err, ErrInvalidBankCode, input)
+	}
+
+	assert.True(t, IsBankSupportedOnUSSD("44"))
+	assert.True(t, IsBankSupportedOnUSSD(" 58"))
+	assert.False(t, IsBankSupportedOnUSSD("0044"))
+
+	name, ok := BankNameForUSSDCode("57")
+	assert.True(t, ok)
+	assert.Equal(t, "Zenith Bank", name)
+}
+
+func TestGenerateUSSDNormalizesBankCode(t *testing.T) {
+
+	var sentBankCodes []interface{}
+
+	testAccount := newTestAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == baseLoginUrl {
+			writeTestLoginResponse(rw)
+			return
+		}
+
+		if req.URL.String() == baseUssdTransaction {
+			var payload map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&payload)
+			sentBankCodes = append(sentBankCodes, payload["bankCode"])
+			rw.Header().Add("content-type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"transactionRef": "0000000000001070108", "ussdString": "*901*000*1234#", "amount": 2000}`))
+			return
+		}
+
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	apiClient, err := NewClient(&testAccount, server.URL, NewMockStore(), server.Client())
+	if err != nil {
+		t.Fatalf("Did not expect client creation to fail: %v", err)
+	}
+
+	for _, bankCode := range []string{"44", "044"} {
+		if _, err := apiClient.GenerateUSSD("ussd-ref", 2000, bankCode); err != nil {
+			t.Fatalf("Did not expect call to fail: %v", err)
+		}
+	}
+	assert.Equal(t, []interface{}{"044", "044"}, sentBankCodes)
+
+	_, err = apiClient.GenerateUSSD("ussd-ref", 2000, "4a")
+	assert.ErrorIs(t, err, ErrInvalidBankCode)
+	assert.Len(t, sentBankCodes, 2)
+}
@@ -7,6 +7,10 @@ import (
 "errors"
 )
 
+//DesEncrypt encrypts src with triple DES in ECB mode and returns it hex
+//encoded, this is how the pin is sent to the api with the session id as key.
+//src is zero padded to the block size and key is zero padded or cut to 24
+//bytes
 func DesEncrypt(src, key []byte) (string, error) {
 	out, err := DESedeECBEncrypt(src, key)
 	if err != nil {
@@ -16,6 +20,15 @@ func DesEncrypt(src, key []byte) (string, error) {
 	return base64Encrypted, nil
 }
 
+//DesDecrypt reverses DesEncrypt, the zero padding is removed from the result
+func DesDecrypt(hexStr string, key []byte) ([]byte, error) {
+	crypted, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, err
+	}
+	return DESedeECBDecrypt(crypted, key)
+}
+
 // DESedeECBEncrypt ...
 func DESedeECBEncrypt(origData, key []byte) ([]byte, error) {
 	tkey := make([]byte, 24)
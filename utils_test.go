@@ -0,0 +1,52 @@
+package readycash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//des vectors generated with openssl enc -des-ede3 -nopad using the zero
+//padded key and data
+var desTestVectors = []struct {
+	name      string
+	data      string
+	key       string
+	encrypted string
+}{
+	{name: "short session key", data: "1234", key: "1234", encrypted: "7e49bab92e89ee6d"},
+	{name: "full length key", data: "1234", key: "session-id-0001-abcdefgh", encrypted: "90943efe3b13c889"},
+	{name: "multiple blocks", data: "123456789", key: "session-id-0001-abcdefgh", encrypted: "4c6d9b8e550b3cfebb05ac7aa7ce4c64"},
+}
+
+func TestDesEncryptKnownVectors(t *testing.T) {
+	for _, tt := range desTestVectors {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := DesEncrypt([]byte(tt.data), []byte(tt.key))
+			if err != nil {
+				t.Fatalf("Did not expect encryption to fail: %v", err)
+			}
+			assert.Equal(t, tt.encrypted, encrypted)
+		})
+	}
+}
+
+func TestDesDecryptKnownVectors(t *testing.T) {
+	for _, tt := range desTestVectors {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := DesDecrypt(tt.encrypted, []byte(tt.key))
+			if err != nil {
+				t.Fatalf("Did not expect decryption to fail: %v", err)
+			}
+			assert.Equal(t, tt.data, string(data))
+		})
+	}
+}
+
+func TestDesDecryptRejectsInvalidInput(t *testing.T) {
+	_, err := DesDecrypt("not hex", []byte("1234"))
+	assert.Error(t, err)
+
+	_, err = DesDecrypt("7e49ba", []byte("1234"))
+	assert.Error(t, err)
+}